@@ -4,26 +4,96 @@ Package async provides an asynchronous appender for gol.
 package async
 
 import (
+	"context"
 	"sync"
 	"time"
 
 	"github.com/goburrow/gol"
 )
 
+// OverflowPolicy specifies what Appender does when a channel buffer is full.
+type OverflowPolicy int
+
+// Overflow policies
+const (
+	// Block waits until the channel has space.
+	Block OverflowPolicy = iota
+	// BlockTimeout waits until the channel has space or the block timeout
+	// expires, in which case the event is dropped.
+	BlockTimeout
+	// DropNewest drops the event being appended.
+	DropNewest
+	// DropOldest drops the oldest pending event in the channel to make space
+	// for the event being appended.
+	DropOldest
+)
+
+const defaultDrainTimeout = 10 * time.Second
+
 // Appender sends the logging event to all appenders asynchronously.
 // It implements gol.Appender.
 type Appender struct {
-	// drainTimeout is maximum duration before timing out flush a channel.
+	// drainTimeout is maximum duration before timing out flush a channel
+	// in Stop.
 	drainTimeout time.Duration
 
-	wg        sync.WaitGroup
-	appenders []gol.Appender
-	chans     []chan *gol.LoggingEvent
+	overflowPolicy OverflowPolicy
+	blockTimeout   time.Duration
+	// discardThreshold is the number of pending events in a channel from
+	// which events at level Info or lower are discarded.
+	discardThreshold int
+
+	// dropLogger receives periodic warnings of dropped events.
+	dropLogger   gol.Logger
+	dropInterval time.Duration
+	// dropReported is the number of dropped events already reported.
+	dropReported uint64
+
+	// maxFailures is number of consecutive failures (panics) of a downstream
+	// appender before it is disabled for failureBackoff.
+	maxFailures    int
+	failureBackoff time.Duration
+
+	// batchSize and batchLinger control batching delivery.
+	batchSize   int
+	batchLinger time.Duration
+
+	// bufSize is the buffer size of channels receiving appended events.
+	bufSize int
+	// sinks are downstream appenders in the order given.
+	sinks []*sink
+	// queues receive appended events.
+	queues []*queue
+	// workers are queues which have a go routine delivering events to their
+	// sinks. They are the same as queues unless in shared mode, where a
+	// dispatcher forwards events from the only queue to workers.
+	workers []*queue
+	shared  bool
 
+	// lifecycleMu serializes Start and Stop.
+	lifecycleMu sync.Mutex
+	// wg is for go routines receiving events.
+	wg sync.WaitGroup
+	// reportWG is for the go routine reporting dropped events.
+	reportWG sync.WaitGroup
+	// reportFinish stops reporting dropped events.
+	reportFinish chan struct{}
+	// stopped is closed when all go routines exited after Shutdown.
+	stopped chan struct{}
+
+	// mu guards started, finish, abort, drainCtx, sinks and queues. Append
+	// holds the read lock while sending events so Stop can not begin until
+	// they are accepted or aborted.
+	mu sync.RWMutex
 	// started in an indicator for this appender state.
 	started bool
 	// finish is used in Start and Stop
 	finish chan struct{}
+	// abort is closed when the context given to Shutdown is done, so Append
+	// blocked on a full channel gives up.
+	abort chan struct{}
+	// drainCtx is the context flushing channels after Stop.
+	drainCtx context.Context
 }
 
 // NewAppender allocates and returns a new Appender.
@@ -36,83 +106,255 @@ func NewAppender(appenders ...gol.Appender) *Appender {
 // of bufSize for each appender channel.
 func NewAppenderWithBufSize(bufSize int, appenders ...gol.Appender) *Appender {
 	a := &Appender{
-		appenders:    appenders,
-		drainTimeout: 10 * time.Second,
+		drainTimeout: defaultDrainTimeout,
+		bufSize:      bufSize,
 	}
-	a.chans = make([]chan *gol.LoggingEvent, len(appenders))
+	a.sinks = make([]*sink, len(appenders))
+	a.queues = make([]*queue, len(appenders))
 	for i := range appenders {
-		a.chans[i] = make(chan *gol.LoggingEvent, bufSize)
+		a.queues[i] = newQueue(bufSize)
+		a.sinks[i] = newSink(appenders[i], a.queues[i], a.queues[i])
+		a.queues[i].sinks = []*sink{a.sinks[i]}
 	}
+	a.workers = a.queues
 	return a
 }
 
-// Append sends the event to all appenders.
+// Append sends a copy of the event to all appenders as the event is released
+// once Append returns.
+// The copy is shared among appenders so they must not modify it.
 func (a *Appender) Append(e *gol.LoggingEvent) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	if !a.started {
 		// Skip the event if appender is stopped.
+		for _, s := range a.sinks {
+			s.drop(DropStopped, e.Level)
+		}
 		return
 	}
-	for _, c := range a.chans {
-		// FIXME: This is still blocking if a channel buffer is full.
-		c <- e
+	e = e.Clone()
+	for _, q := range a.queues {
+		a.enqueue(q, e)
 	}
 }
 
-// Start starts go routines for each writer.
-func (a *Appender) Start() {
-	if a.started {
-		return
+// Flush waits until all events appended before calling Flush are delivered,
+// then flushes downstream appenders. It returns the first error from
+// downstream appenders.
+func (a *Appender) Flush() error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	var err error
+	if !a.started {
+		for _, s := range a.sinks {
+			if e := gol.FlushAppender(s.appender); e != nil && err == nil {
+				err = e
+			}
+		}
+		return err
+	}
+	dones := make([]<-chan error, len(a.queues))
+	for i, q := range a.queues {
+		q := q
+		dones[i] = q.do(func() error {
+			if a.shared {
+				return a.flushShared(q)
+			}
+			return a.flushQueue(q)
+		})
+	}
+	for _, done := range dones {
+		if e := <-done; e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// SetOverflowPolicy changes the policy applied when a channel buffer is full.
+// timeout is only used with BlockTimeout policy.
+// It must be called before Start.
+func (a *Appender) SetOverflowPolicy(policy OverflowPolicy, timeout time.Duration) {
+	a.overflowPolicy = policy
+	a.blockTimeout = timeout
+}
+
+// SetDiscardThreshold discards events at Trace, Debug and Info level when a
+// channel has at least threshold pending events. Warn and Error events are
+// never discarded by the threshold. Zero disables discarding.
+// It must be called before Start.
+func (a *Appender) SetDiscardThreshold(threshold int) {
+	a.discardThreshold = threshold
+}
+
+// SetFailureBackoff disables a downstream appender for backoff duration after
+// it panics failures times consecutively. Events are dropped while the
+// appender is disabled. Panics are always recovered and reported to
+// gol.ReportError. Zero failures never disables appenders.
+// It must be called before Start.
+func (a *Appender) SetFailureBackoff(failures int, backoff time.Duration) {
+	a.maxFailures = failures
+	a.failureBackoff = backoff
+}
+
+// SetDropReporter makes the appender log a warning with number of events
+// dropped or timed out to logger every interval when there are new ones.
+// logger must not write to this appender.
+// It must be called before Start.
+func (a *Appender) SetDropReporter(logger gol.Logger, interval time.Duration) {
+	a.dropLogger = logger
+	a.dropInterval = interval
+}
+
+// Start starts go routines for each writer. It always returns nil.
+// The appender can be started again after Stop. If go routines of the
+// previous run are still delivering events, Start waits until they exit.
+// Downstream appenders are not started.
+func (a *Appender) Start() error {
+	a.lifecycleMu.Lock()
+	defer a.lifecycleMu.Unlock()
+
+	if a.isStarted() {
+		return nil
+	}
+	// Wait for go routines of the previous run which timed out.
+	if a.stopped != nil {
+		<-a.stopped
+	}
+
+	for _, s := range a.sinks {
+		a.setBatcher(s)
 	}
-	a.finish = make(chan struct{})
-	a.wg.Add(len(a.chans))
-	for i, c := range a.chans {
-		go a.receive(c, a.appenders[i])
+	finish := make(chan struct{})
+	if a.shared {
+		// Workers are finished by the dispatcher.
+		workerFinish := make(chan struct{})
+		a.wg.Add(len(a.workers) + 1)
+		for _, w := range a.workers {
+			go a.receive(w, workerFinish)
+		}
+		go a.dispatch(a.queues[0], finish, workerFinish)
+	} else {
+		a.wg.Add(len(a.workers))
+		for _, w := range a.workers {
+			go a.receive(w, finish)
+		}
 	}
+	if a.dropLogger != nil && a.dropInterval > 0 {
+		a.reportFinish = make(chan struct{})
+		a.reportWG.Add(1)
+		go a.reportDropped(a.reportFinish)
+	}
+
+	a.mu.Lock()
+	a.finish = finish
+	a.abort = make(chan struct{})
 	a.started = true
+	a.mu.Unlock()
+	return nil
 }
 
-// Stop stops and waits until all go routines exited.
-// Once Stop is called, this appender can not be started again.
-func (a *Appender) Stop() {
-	if !a.started {
-		return
+// Stop stops and waits until all go routines exited or the drain timeout
+// (10 seconds) expires, in which case it returns context.DeadlineExceeded.
+// Downstream appenders are not stopped.
+func (a *Appender) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.drainTimeout)
+	defer cancel()
+	return a.Shutdown(ctx)
+}
+
+// Shutdown stops accepting new events and waits until all pending events are
+// delivered or ctx is done, in which case it returns ctx.Err().
+// Events not delivered when ctx is done are discarded and counted as
+// timed out in Stats. Events still blocked in Append when ctx is done are
+// dropped as DropStopped.
+func (a *Appender) Shutdown(ctx context.Context) error {
+	a.lifecycleMu.Lock()
+	defer a.lifecycleMu.Unlock()
+
+	if !a.isStarted() {
+		return nil
 	}
+	// Wait for appending events to be accepted, or abort them when ctx is
+	// done.
+	accepted := make(chan struct{})
+	go func(abort chan struct{}) {
+		select {
+		case <-ctx.Done():
+			close(abort)
+		case <-accepted:
+		}
+	}(a.abort)
+	a.mu.Lock()
+	close(accepted)
 	a.started = false
+	a.drainCtx = ctx
 	close(a.finish)
-	a.wg.Wait()
-	// all channels can be closed now, but then users can not start this
-	// appender again.
+	a.mu.Unlock()
+
+	reportFinish := a.reportFinish
+	a.reportFinish = nil
+	stopped := make(chan struct{})
+	a.stopped = stopped
+	go func() {
+		a.wg.Wait()
+		if reportFinish != nil {
+			close(reportFinish)
+			a.reportWG.Wait()
+		}
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
-func (a *Appender) receive(c chan *gol.LoggingEvent, appender gol.Appender) {
-	defer a.wg.Done()
+// Dependencies returns the downstream appenders.
+func (a *Appender) Dependencies() []interface{} {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 
-	for {
-		select {
-		case <-a.finish:
-			a.flush(c, appender)
-			return
-		case e := <-c:
-			appender.Append(e)
-		}
+	deps := make([]interface{}, len(a.sinks))
+	for i, s := range a.sinks {
+		deps[i] = s.appender
 	}
+	return deps
 }
 
-// flush sends all pending data in the channel to writer or timeout after
-// maximum of durationTimeout and the appender timeout.
-func (a *Appender) flush(c chan *gol.LoggingEvent, appender gol.Appender) {
-	timeout := time.After(a.drainTimeout)
+func (a *Appender) isStarted() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.started
+}
+
+// reportDropped periodically logs number of new dropped events.
+func (a *Appender) reportDropped(finish chan struct{}) {
+	defer a.reportWG.Done()
+
+	ticker := time.NewTicker(a.dropInterval)
+	defer ticker.Stop()
 	for {
 		select {
-		case <-timeout:
-			// Timeout channel has higher priority.
-			return
-		case e := <-c:
-			appender.Append(e)
-			// Continue reading from the appender.
-		default:
-			// Channel is empty.
+		case <-finish:
+			a.logDropped()
 			return
+		case <-ticker.C:
+			a.logDropped()
 		}
 	}
 }
+
+func (a *Appender) logDropped() {
+	stats := a.Stats()
+	total := stats.TotalDropped() + stats.TimedOut
+	if total > a.dropReported {
+		a.dropLogger.Warnf("%d events dropped by asynchronous appender", total-a.dropReported)
+		a.dropReported = total
+	}
+}
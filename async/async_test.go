@@ -2,14 +2,22 @@ package async
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/goburrow/gol"
 )
 
-var _ (gol.Appender) = (*Appender)(nil)
+var (
+	_ (gol.Appender)  = (*Appender)(nil)
+	_ (gol.Flusher)   = (*Appender)(nil)
+	_ (gol.Lifecycle) = (*Appender)(nil)
+	_ (gol.Dependent) = (*Appender)(nil)
+)
 
 // channelWriter is used for testing async appender
 type channelWriter chan string
@@ -117,3 +125,303 @@ func TestAppenderLifeCycle(t *testing.T) {
 		t.Fatalf("unexpected message: %v", buf.String())
 	}
 }
+
+// lockedWriter collects written messages.
+type lockedWriter struct {
+	mu sync.Mutex
+	s  []string
+}
+
+func (w *lockedWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	w.s = append(w.s, string(b))
+	w.mu.Unlock()
+	return len(b), nil
+}
+
+func TestAppenderEventOwnership(t *testing.T) {
+	writers := [...]*lockedWriter{&lockedWriter{}, &lockedWriter{}}
+	appender := NewAppenderWithBufSize(100,
+		gol.NewAppender(writers[0]),
+		gol.NewAppender(writers[1]),
+	)
+	logger := gol.New("async", nil)
+	logger.SetLevel(gol.Info)
+	logger.SetAppender(appender)
+
+	appender.Start()
+	const routines, count = 8, 200
+	var wg sync.WaitGroup
+	wg.Add(routines)
+	for i := 0; i < routines; i++ {
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < count; j++ {
+				logger.Infof("routine %d message %d %s", i, j, strings.Repeat("x", j%50))
+			}
+		}(i)
+	}
+	wg.Wait()
+	appender.Stop()
+
+	for _, w := range writers {
+		if len(w.s) != routines*count {
+			t.Fatalf("unexpected message count: %d", len(w.s))
+		}
+		seen := make(map[string]bool, len(w.s))
+		for _, s := range w.s {
+			var i, j int
+			idx := strings.Index(s, "async: ")
+			if idx < 0 {
+				t.Fatalf("unexpected message: %q", s)
+			}
+			msg := s[idx+len("async: "):]
+			if _, err := fmt.Sscanf(msg, "routine %d message %d", &i, &j); err != nil {
+				t.Fatalf("unexpected message %q: %v", msg, err)
+			}
+			if msg != fmt.Sprintf("routine %d message %d %s\n", i, j, strings.Repeat("x", j%50)) {
+				t.Fatalf("corrupted message: %q", msg)
+			}
+			seen[msg] = true
+		}
+		if len(seen) != routines*count {
+			t.Fatalf("unexpected unique message count: %d", len(seen))
+		}
+	}
+}
+
+// blockingWriter blocks on the first Write until released.
+type blockingWriter struct {
+	lockedWriter
+
+	entered chan struct{}
+	release chan struct{}
+}
+
+func newBlockingWriter() *blockingWriter {
+	return &blockingWriter{
+		entered: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+}
+
+func (w *blockingWriter) Write(b []byte) (int, error) {
+	select {
+	case w.entered <- struct{}{}:
+		<-w.release
+	default:
+	}
+	return w.lockedWriter.Write(b)
+}
+
+func (w *blockingWriter) messages() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var msgs []string
+	for _, s := range w.s {
+		msgs = append(msgs, strings.TrimSpace(s[strings.Index(s, ": ")+2:]))
+	}
+	return strings.Join(msgs, ",")
+}
+
+func newEvent(level gol.Level, msg string) *gol.LoggingEvent {
+	e := &gol.LoggingEvent{
+		Name:  "async",
+		Level: level,
+		Time:  time.Now(),
+	}
+	e.Message.WriteString(msg)
+	return e
+}
+
+// testOverflow appends events while the first event is being written.
+func testOverflow(t *testing.T, appender *Appender, w *blockingWriter, events []*gol.LoggingEvent, expected string) {
+	appender.Start()
+	appender.Append(events[0])
+	<-w.entered
+	for _, e := range events[1:] {
+		appender.Append(e)
+	}
+	close(w.release)
+	appender.Stop()
+	if w.messages() != expected {
+		t.Fatalf("unexpected messages: %v, expected: %v", w.messages(), expected)
+	}
+}
+
+func TestAppenderOverflowPolicy(t *testing.T) {
+	events := []*gol.LoggingEvent{
+		newEvent(gol.Info, "1"),
+		newEvent(gol.Info, "2"),
+		newEvent(gol.Info, "3"),
+		newEvent(gol.Error, "4"),
+	}
+
+	w := newBlockingWriter()
+	appender := NewAppenderWithBufSize(2, gol.NewAppender(w))
+	appender.SetOverflowPolicy(DropNewest, 0)
+	testOverflow(t, appender, w, events, "1,2,3")
+
+	w = newBlockingWriter()
+	appender = NewAppenderWithBufSize(2, gol.NewAppender(w))
+	appender.SetOverflowPolicy(DropOldest, 0)
+	testOverflow(t, appender, w, events, "1,3,4")
+
+	w = newBlockingWriter()
+	appender = NewAppenderWithBufSize(2, gol.NewAppender(w))
+	appender.SetOverflowPolicy(BlockTimeout, 10*time.Millisecond)
+	start := time.Now()
+	testOverflow(t, appender, w, events, "1,2,3")
+	if time.Since(start) < 10*time.Millisecond {
+		t.Fatal("append must be blocked until timeout")
+	}
+}
+
+func TestAppenderDiscardThreshold(t *testing.T) {
+	events := []*gol.LoggingEvent{
+		newEvent(gol.Info, "1"),
+		newEvent(gol.Debug, "2"),
+		newEvent(gol.Info, "3"),
+		newEvent(gol.Info, "4"),
+		newEvent(gol.Warn, "5"),
+		newEvent(gol.Trace, "6"),
+		newEvent(gol.Error, "7"),
+	}
+
+	w := newBlockingWriter()
+	appender := NewAppenderWithBufSize(5, gol.NewAppender(w))
+	appender.SetDiscardThreshold(2)
+	testOverflow(t, appender, w, events, "1,2,3,5,7")
+}
+
+func TestAppenderConcurrentLifeCycle(t *testing.T) {
+	var w lockedWriter
+	appender := NewAppenderWithBufSize(5, gol.NewAppender(&w))
+
+	const routines, count = 4, 500
+	var wg sync.WaitGroup
+	wg.Add(routines + 1)
+	for i := 0; i < routines; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < count; j++ {
+				appender.Append(newEvent(gol.Info, "run"))
+			}
+		}()
+	}
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			appender.Start()
+			time.Sleep(100 * time.Microsecond)
+			appender.Stop()
+		}
+	}()
+	wg.Wait()
+	appender.Stop()
+
+	stats := appender.Stats()
+	if stats.Enqueued+stats.TotalDropped() != routines*count {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if stats.Enqueued != stats.Delivered || stats.TimedOut != 0 || uint64(len(w.s)) != stats.Delivered {
+		t.Fatalf("unexpected stats: %+v, messages: %d", stats, len(w.s))
+	}
+}
+
+func TestAppenderShutdown(t *testing.T) {
+	var w lockedWriter
+	appender := NewAppenderWithBufSize(10, gol.NewAppender(&w))
+	if err := appender.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	appender.Start()
+	appender.Start()
+	for i := 0; i < 10; i++ {
+		appender.Append(newEvent(gol.Info, "run"))
+	}
+	if err := appender.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	appender.Append(newEvent(gol.Info, "stopped"))
+	if len(w.s) != 10 {
+		t.Fatalf("unexpected messages: %v", w.s)
+	}
+	stats := appender.Stats()
+	if stats.Dropped[DropStopped][gol.Info] != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestAppenderShutdownBlocked(t *testing.T) {
+	w := newBlockingWriter()
+	appender := NewAppenderWithBufSize(1, gol.NewAppender(w))
+	appender.Start()
+	logger := gol.New("async", nil)
+	logger.SetLevel(gol.Info)
+	logger.SetAppender(appender)
+
+	appended := make(chan struct{})
+	go func() {
+		defer close(appended)
+		for i := 0; i < 3; i++ {
+			logger.Infof("run %d", i)
+		}
+	}()
+	<-w.entered
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- appender.Shutdown(ctx)
+	}()
+	select {
+	case err := <-shutdown:
+		if err != context.DeadlineExceeded {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("shutdown is blocked")
+	}
+	select {
+	case <-appended:
+	case <-time.After(time.Second):
+		t.Fatal("append is blocked")
+	}
+	close(w.release)
+	stats := appender.Stats()
+	if stats.Enqueued != 2 || stats.Dropped[DropStopped][gol.Info] != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+// flushAppender counts Flush calls.
+type flushAppender struct {
+	gol.Appender
+	flushes int
+}
+
+func (f *flushAppender) Flush() error {
+	f.flushes++
+	return nil
+}
+
+func TestAppenderFlush(t *testing.T) {
+	w := &slowWriter{5 * time.Millisecond, nil}
+	downstream := &flushAppender{Appender: gol.NewAppender(w)}
+	appender := NewAppender(downstream)
+	if err := appender.Flush(); err != nil || downstream.flushes != 1 {
+		t.Fatalf("unexpected flush: %v %d", err, downstream.flushes)
+	}
+	appender.Start()
+	defer appender.Stop()
+	for i := 0; i < 5; i++ {
+		appender.Append(newEvent(gol.Info, "run"))
+	}
+	if err := appender.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(w.s) != 5 || downstream.flushes != 2 {
+		t.Fatalf("unexpected messages: %v, flushes: %d", w.s, downstream.flushes)
+	}
+}
@@ -0,0 +1,89 @@
+package async
+
+import (
+	"runtime/debug"
+	"time"
+
+	"github.com/goburrow/gol"
+)
+
+// BatchAppender is implemented by appenders which can append many events at
+// once, e.g. network appenders.
+type BatchAppender interface {
+	gol.Appender
+	// AppendBatch appends all events. The slice is reused after AppendBatch
+	// returns so it must not be retained, though the events can be.
+	AppendBatch([]*gol.LoggingEvent)
+}
+
+// SetBatch enables batching delivery to downstream appenders implementing
+// BatchAppender. Events are accumulated up to size events or linger duration
+// since the first event in the batch. If linger is zero, the batch is
+// delivered as soon as there are no more pending events.
+// Other appenders still receive events one by one.
+// It must be called before Start.
+func (a *Appender) SetBatch(size int, linger time.Duration) {
+	a.batchSize = size
+	a.batchLinger = linger
+}
+
+// setBatcher enables batching for the sink if supported.
+func (a *Appender) setBatcher(s *sink) {
+	s.batcher = nil
+	if b, ok := s.appender.(BatchAppender); ok && a.batchSize > 1 {
+		s.batcher = b
+	}
+}
+
+// deliverBatch sends pending batch to the downstream appender.
+func (a *Appender) deliverBatch(s *sink) {
+	n := len(s.batch)
+	if n == 0 {
+		return
+	}
+	if err := safeAppendBatch(s.batcher, s.batch); err != nil {
+		for _, e := range s.batch {
+			s.drop(DropPanic, e.Level)
+		}
+		a.failed(s, err)
+	} else {
+		s.failures = 0
+		s.delivered(n)
+	}
+	s.clearBatch()
+}
+
+// deliverBatches sends pending batches of all sinks of the queue.
+func (a *Appender) deliverBatches(q *queue) {
+	for _, s := range q.sinks {
+		a.deliverBatch(s)
+	}
+}
+
+// hasBatch checks if any sink of the queue has pending batch.
+func (q *queue) hasBatch() bool {
+	for _, s := range q.sinks {
+		if len(s.batch) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *sink) clearBatch() {
+	for i := range s.batch {
+		s.batch[i] = nil
+	}
+	s.batch = s.batch[:0]
+}
+
+// safeAppendBatch returns a gol.PanicError if the appender panics.
+func safeAppendBatch(b BatchAppender, events []*gol.LoggingEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &gol.PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	b.AppendBatch(events)
+	return nil
+}
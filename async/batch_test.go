@@ -0,0 +1,126 @@
+package async
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goburrow/gol"
+)
+
+// batchAppender records sizes of batches.
+type batchAppender struct {
+	mu      sync.Mutex
+	batches []int
+	events  int
+	appends int
+}
+
+func (b *batchAppender) Append(*gol.LoggingEvent) {
+	b.mu.Lock()
+	b.appends++
+	b.mu.Unlock()
+}
+
+func (b *batchAppender) AppendBatch(events []*gol.LoggingEvent) {
+	b.mu.Lock()
+	b.batches = append(b.batches, len(events))
+	b.events += len(events)
+	b.mu.Unlock()
+}
+
+func (b *batchAppender) count() (int, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.events, len(b.batches)
+}
+
+func TestAppenderBatchSize(t *testing.T) {
+	b := &batchAppender{}
+	w := newBlockingWriter()
+	appender := NewAppenderWithBufSize(10, b, gol.NewAppender(w))
+	appender.SetBatch(3, time.Hour)
+	appender.Start()
+
+	for i := 0; i < 7; i++ {
+		appender.Append(newEvent(gol.Info, "1"))
+	}
+	<-w.entered
+	close(w.release)
+	for i := 0; i < 100; i++ {
+		if events, _ := b.count(); events == 6 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	appender.Stop()
+	if b.appends != 0 || b.events != 7 || len(b.batches) != 3 ||
+		b.batches[0] != 3 || b.batches[1] != 3 || b.batches[2] != 1 {
+		t.Fatalf("unexpected batches: %+v", b)
+	}
+	// Non-batch appender receives events individually.
+	if len(w.s) != 7 {
+		t.Fatalf("unexpected messages: %v", w.s)
+	}
+	stats := appender.Stats()
+	if stats.Delivered != 14 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestAppenderBatchLinger(t *testing.T) {
+	b := &batchAppender{}
+	appender := NewAppender(b)
+	appender.SetBatch(10, 10*time.Millisecond)
+	appender.Start()
+	defer appender.Stop()
+
+	appender.Append(newEvent(gol.Info, "1"))
+	appender.Append(newEvent(gol.Info, "2"))
+	for i := 0; i < 1000; i++ {
+		if events, _ := b.count(); events == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if events, batches := b.count(); events != 2 || batches != 1 {
+		t.Fatalf("unexpected batches: %+v", b)
+	}
+}
+
+func TestAppenderBatchNoLinger(t *testing.T) {
+	b := &batchAppender{}
+	appender := NewAppender(b)
+	appender.SetBatch(10, 0)
+	appender.Start()
+	defer appender.Stop()
+
+	appender.Append(newEvent(gol.Info, "1"))
+	for i := 0; i < 1000; i++ {
+		if events, _ := b.count(); events == 1 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("unexpected batches: %+v", b)
+}
+
+func TestSharedAppenderBatchNoLinger(t *testing.T) {
+	b := &batchAppender{}
+	w := newBlockingWriter()
+	appender := NewSharedAppender(10, 1, b, gol.NewAppender(w))
+	appender.SetBatch(10, 0)
+	appender.Start()
+
+	appender.Append(newEvent(gol.Info, "1"))
+	<-w.entered
+	for i := 0; i < 5; i++ {
+		appender.Append(newEvent(gol.Info, "2"))
+	}
+	// Events pending in the shared channel are batched.
+	close(w.release)
+	appender.Stop()
+	if b.events != 6 || len(b.batches) != 1 {
+		t.Fatalf("unexpected batches: %+v", b)
+	}
+}
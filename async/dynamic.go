@@ -0,0 +1,153 @@
+package async
+
+import (
+	"errors"
+
+	"github.com/goburrow/gol"
+)
+
+var errAppenderNotFound = errors.New("async: appender not found")
+
+// Add attaches a downstream appender. If the Appender is started, the new
+// appender receives events appended after Add returns. In shared mode, Add
+// blocks appending until events pending in the shared channel are forwarded
+// to workers.
+func (a *Appender) Add(appender gol.Appender) {
+	a.lifecycleMu.Lock()
+	defer a.lifecycleMu.Unlock()
+
+	if !a.isStarted() {
+		a.waitStopped()
+	}
+	if a.shared {
+		w := a.leastLoadedWorker()
+		s := newSink(appender, a.queues[0], w)
+		a.setBatcher(s)
+
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if a.started {
+			// Attach after pending events so it does not receive them.
+			<-a.queues[0].do(func() error {
+				a.forwardPending(a.queues[0])
+				return <-w.do(func() error {
+					w.sinks = append(w.sinks, s)
+					return nil
+				})
+			})
+		} else {
+			w.sinks = append(w.sinks, s)
+		}
+		a.sinks = append(a.sinks, s)
+		a.queues[0].sinks = a.sinks
+		return
+	}
+	q := newQueue(a.bufSize)
+	s := newSink(appender, q, q)
+	a.setBatcher(s)
+	q.sinks = []*sink{s}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.sinks = append(a.sinks, s)
+	a.queues = append(a.queues, q)
+	a.workers = a.queues
+	if a.started {
+		a.wg.Add(1)
+		go a.receive(q, a.finish)
+	}
+}
+
+// Remove detaches a downstream appender. If the Appender is started, Remove
+// waits until events pending for the appender are delivered, then flushes it
+// and returns the flush error. The appender can then be stopped or closed.
+// Statistics of the removed appender are no longer included in Stats.
+func (a *Appender) Remove(appender gol.Appender) error {
+	a.lifecycleMu.Lock()
+	defer a.lifecycleMu.Unlock()
+
+	if !a.isStarted() {
+		a.waitStopped()
+	}
+	a.mu.Lock()
+	i := a.indexOf(appender)
+	if i < 0 {
+		a.mu.Unlock()
+		return errAppenderNotFound
+	}
+	s := a.sinks[i]
+	a.sinks = append(a.sinks[:i:i], a.sinks[i+1:]...)
+	if a.shared {
+		a.queues[0].sinks = a.sinks
+	} else {
+		a.queues = append(a.queues[:i:i], a.queues[i+1:]...)
+		a.workers = a.queues
+	}
+	started := a.started
+	a.mu.Unlock()
+
+	// No more events are sent to the sink.
+	w := s.worker
+	if !started {
+		w.sinks = removeSink(w.sinks, s)
+		return nil
+	}
+	if !a.shared {
+		return <-w.do(func() error {
+			w.closed = true
+			return a.flushQueue(w)
+		})
+	}
+	// Forward events pending for the sink before detaching it.
+	return <-a.queues[0].do(func() error {
+		a.forwardPending(a.queues[0])
+		return <-w.do(func() error {
+			w.sinks = removeSink(w.sinks, s)
+			a.deliverBatch(s)
+			return gol.FlushAppender(s.appender)
+		})
+	})
+}
+
+// waitStopped waits for go routines of the previous run which timed out.
+func (a *Appender) waitStopped() {
+	if a.stopped != nil {
+		<-a.stopped
+	}
+}
+
+// indexOf returns index of the sink of appender or -1 if not found.
+func (a *Appender) indexOf(appender gol.Appender) int {
+	for i, s := range a.sinks {
+		if s.appender == appender {
+			return i
+		}
+	}
+	return -1
+}
+
+// leastLoadedWorker returns the worker with fewest sinks.
+func (a *Appender) leastLoadedWorker() *queue {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	counts := make(map[*queue]int, len(a.workers))
+	for _, s := range a.sinks {
+		counts[s.worker]++
+	}
+	w := a.workers[0]
+	for _, v := range a.workers[1:] {
+		if counts[v] < counts[w] {
+			w = v
+		}
+	}
+	return w
+}
+
+func removeSink(sinks []*sink, s *sink) []*sink {
+	for i, v := range sinks {
+		if v == s {
+			return append(sinks[:i:i], sinks[i+1:]...)
+		}
+	}
+	return sinks
+}
@@ -0,0 +1,81 @@
+package async
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goburrow/gol"
+)
+
+func testAddRemove(t *testing.T, appender *Appender, w1 *lockedWriter) {
+	appender.Start()
+	defer appender.Stop()
+	appender.Append(newEvent(gol.Info, "1"))
+
+	w2 := &slowWriter{d: time.Millisecond}
+	a2 := &flushAppender{Appender: gol.NewAppender(w2)}
+	appender.Add(a2)
+	for i := 0; i < 5; i++ {
+		appender.Append(newEvent(gol.Info, "2"))
+	}
+	if err := appender.Remove(a2); err != nil {
+		t.Fatal(err)
+	}
+	// All pending events are delivered and flushed.
+	if len(w2.s) != 5 || a2.flushes != 1 {
+		t.Fatalf("unexpected messages: %v, flushes: %d", w2.s, a2.flushes)
+	}
+	appender.Append(newEvent(gol.Info, "3"))
+	if err := appender.Remove(a2); err != errAppenderNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := appender.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(w2.s) != 5 {
+		t.Fatalf("unexpected messages: %v", w2.s)
+	}
+	w1.mu.Lock()
+	n := len(w1.s)
+	w1.mu.Unlock()
+	if n != 7 {
+		t.Fatalf("unexpected messages: %v", w1.s)
+	}
+	stats := appender.Stats()
+	if len(stats.Queues) != 1 || stats.Queues[0].Delivered != 7 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestAppenderAddRemove(t *testing.T) {
+	var w lockedWriter
+	testAddRemove(t, NewAppender(gol.NewAppender(&w)), &w)
+}
+
+func TestSharedAppenderAddRemove(t *testing.T) {
+	var w lockedWriter
+	testAddRemove(t, NewSharedAppender(10, 2, gol.NewAppender(&w)), &w)
+}
+
+func TestAppenderAddStopped(t *testing.T) {
+	var w1, w2 lockedWriter
+	a1 := gol.NewAppender(&w1)
+	a2 := gol.NewAppender(&w2)
+	for _, appender := range []*Appender{NewAppender(), NewSharedAppender(10, 2)} {
+		w1.s, w2.s = nil, nil
+		appender.Add(a1)
+		appender.Add(a2)
+		appender.Start()
+		appender.Append(newEvent(gol.Info, "1"))
+		appender.Stop()
+		if err := appender.Remove(a1); err != nil {
+			t.Fatal(err)
+		}
+		appender.Start()
+		appender.Append(newEvent(gol.Info, "2"))
+		appender.Stop()
+		if len(w1.s) != 1 || len(w2.s) != 2 {
+			t.Fatalf("unexpected messages: %v %v", w1.s, w2.s)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package async
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goburrow/gol"
+)
+
+// panicAppender panics when message is "panic".
+type panicAppender struct {
+	mu       sync.Mutex
+	appended int
+}
+
+func (p *panicAppender) Append(e *gol.LoggingEvent) {
+	if e.Message.String() == "panic" {
+		panic("append")
+	}
+	p.mu.Lock()
+	p.appended++
+	p.mu.Unlock()
+}
+
+func (p *panicAppender) AppendBatch(events []*gol.LoggingEvent) {
+	for _, e := range events {
+		p.Append(e)
+	}
+}
+
+func recordErrors() (*[]error, func()) {
+	var mu sync.Mutex
+	var errs []error
+	gol.SetErrorHandler(func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	})
+	return &errs, func() {
+		gol.SetErrorHandler(nil)
+	}
+}
+
+func TestAppenderPanic(t *testing.T) {
+	errs, reset := recordErrors()
+	defer reset()
+
+	p := &panicAppender{}
+	appender := NewAppender(p)
+	appender.Start()
+	appender.Append(newEvent(gol.Info, "panic"))
+	appender.Append(newEvent(gol.Info, "ok"))
+	appender.Stop()
+
+	if p.appended != 1 || len(*errs) != 1 {
+		t.Fatalf("unexpected appended: %d, errors: %v", p.appended, *errs)
+	}
+	if _, ok := (*errs)[0].(*gol.PanicError); !ok {
+		t.Fatalf("unexpected error: %v", (*errs)[0])
+	}
+	stats := appender.Stats()
+	if stats.Delivered != 1 || stats.Dropped[DropPanic][gol.Info] != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestAppenderPanicBatch(t *testing.T) {
+	_, reset := recordErrors()
+	defer reset()
+
+	p := &panicAppender{}
+	appender := NewAppender(p)
+	appender.SetBatch(2, time.Hour)
+	appender.Start()
+	appender.Append(newEvent(gol.Info, "ok"))
+	appender.Append(newEvent(gol.Warn, "panic"))
+	appender.Append(newEvent(gol.Info, "ok"))
+	appender.Stop()
+
+	stats := appender.Stats()
+	if stats.Delivered != 1 || stats.Dropped[DropPanic][gol.Info] != 1 ||
+		stats.Dropped[DropPanic][gol.Warn] != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestAppenderFailureBackoff(t *testing.T) {
+	_, reset := recordErrors()
+	defer reset()
+
+	p := &panicAppender{}
+	appender := NewAppender(p)
+	appender.SetFailureBackoff(2, 20*time.Millisecond)
+	appender.Start()
+	appender.Append(newEvent(gol.Info, "panic"))
+	appender.Append(newEvent(gol.Info, "panic"))
+	// Disabled
+	appender.Append(newEvent(gol.Info, "ok"))
+	appender.Flush()
+	time.Sleep(30 * time.Millisecond)
+	appender.Append(newEvent(gol.Info, "ok"))
+	appender.Stop()
+
+	stats := appender.Stats()
+	if p.appended != 1 || stats.Dropped[DropPanic][gol.Info] != 2 ||
+		stats.Dropped[DropDisabled][gol.Info] != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
@@ -0,0 +1,328 @@
+package async
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/goburrow/gol"
+)
+
+// queue is a channel of events delivered to its sinks by a go routine.
+type queue struct {
+	c chan *gol.LoggingEvent
+	// sinks of a worker queue are only modified by its go routine once
+	// started. sinks of the shared queue are guarded by Appender.mu.
+	sinks []*sink
+	// ctrl receives functions to run in the go routine receiving from the
+	// queue, e.g. flush requests.
+	ctrl chan func()
+	// closed is set by a ctrl function to stop the go routine receiving from
+	// the queue.
+	closed bool
+	// upstream is the shared queue forwarding events to this worker queue
+	// in shared mode.
+	upstream *queue
+	// incoming is number of events taken from upstream by the dispatcher but
+	// not yet received by this queue. It is accessed atomically.
+	incoming int32
+}
+
+func newQueue(bufSize int) *queue {
+	return &queue{
+		c:    make(chan *gol.LoggingEvent, bufSize),
+		ctrl: make(chan func()),
+	}
+}
+
+// do runs fn in the go routine receiving from the queue and returns a channel
+// receiving its result.
+func (q *queue) do(fn func() error) <-chan error {
+	done := make(chan error, 1)
+	q.ctrl <- func() {
+		done <- fn()
+	}
+	return done
+}
+
+// sink is a downstream appender and its delivery state.
+type sink struct {
+	// Counters are accessed atomically and placed first for alignment.
+	counters
+
+	appender gol.Appender
+	// source is the queue accepting events for this sink.
+	source *queue
+	// worker is the queue delivering events to this sink.
+	worker *queue
+
+	// batcher is set when the appender supports batching.
+	batcher BatchAppender
+	// batch contains pending events for batcher.
+	batch []*gol.LoggingEvent
+
+	// failures is number of consecutive failures of the appender.
+	failures int
+	// disabledUntil is set when the appender is disabled.
+	disabledUntil time.Time
+}
+
+func newSink(appender gol.Appender, source, worker *queue) *sink {
+	return &sink{
+		appender: appender,
+		source:   source,
+		worker:   worker,
+	}
+}
+
+// enqueue sends the event to the channel according to the overflow policy.
+// It returns false if the event is dropped. Blocking is aborted when Shutdown
+// times out. It must be called with a.mu read lock held.
+func (a *Appender) enqueue(q *queue, e *gol.LoggingEvent) bool {
+	if a.discardThreshold > 0 && e.Level <= gol.Info && len(q.c) >= a.discardThreshold {
+		q.drop(DropThreshold, e.Level)
+		return false
+	}
+	switch a.overflowPolicy {
+	case BlockTimeout:
+		select {
+		case q.c <- e:
+			q.enqueued()
+			return true
+		default:
+		}
+		timer := time.NewTimer(a.blockTimeout)
+		defer timer.Stop()
+		select {
+		case q.c <- e:
+			q.enqueued()
+			return true
+		case <-timer.C:
+			q.drop(DropTimeout, e.Level)
+			return false
+		case <-a.abort:
+			q.drop(DropStopped, e.Level)
+			return false
+		}
+	case DropNewest:
+		select {
+		case q.c <- e:
+			q.enqueued()
+			return true
+		default:
+			q.drop(DropOverflow, e.Level)
+			return false
+		}
+	case DropOldest:
+		for {
+			select {
+			case q.c <- e:
+				q.enqueued()
+				return true
+			default:
+			}
+			// Make space by removing the oldest event.
+			select {
+			case old := <-q.c:
+				q.drop(DropOverflow, old.Level)
+			default:
+			}
+		}
+	default:
+		select {
+		case q.c <- e:
+			q.enqueued()
+			return true
+		case <-a.abort:
+			q.drop(DropStopped, e.Level)
+			return false
+		}
+	}
+}
+
+// receive delivers events from the queue to its sinks until finish is closed.
+func (a *Appender) receive(q *queue, finish chan struct{}) {
+	defer a.wg.Done()
+
+	// linger is set when a batch is pending.
+	var linger *time.Timer
+	var lingerC <-chan time.Time
+	for {
+		// Finish has higher priority.
+		select {
+		case <-finish:
+			a.flush(q)
+			return
+		default:
+		}
+		select {
+		case <-finish:
+			a.flush(q)
+			return
+		case e := <-q.c:
+			if q.upstream != nil {
+				atomic.AddInt32(&q.incoming, -1)
+			}
+			a.deliverAll(q, e)
+			if a.batchLinger <= 0 && !q.hasPending() {
+				// Nothing more to batch.
+				a.deliverBatches(q)
+			}
+		case <-lingerC:
+			a.deliverBatches(q)
+		case fn := <-q.ctrl:
+			fn()
+			if q.closed {
+				if linger != nil {
+					linger.Stop()
+				}
+				return
+			}
+		}
+		pending := q.hasBatch()
+		if pending && lingerC == nil && a.batchLinger > 0 {
+			linger = time.NewTimer(a.batchLinger)
+			lingerC = linger.C
+		} else if !pending && lingerC != nil {
+			linger.Stop()
+			lingerC = nil
+		}
+	}
+}
+
+// hasPending returns true if more events are coming to the queue, including
+// those in the shared queue in shared mode.
+func (q *queue) hasPending() bool {
+	if len(q.c) > 0 {
+		return true
+	}
+	if q.upstream != nil {
+		return atomic.LoadInt32(&q.incoming) > 0 || len(q.upstream.c) > 0
+	}
+	return false
+}
+
+// deliverAll sends the event to all sinks of the queue.
+func (a *Appender) deliverAll(q *queue, e *gol.LoggingEvent) {
+	for _, s := range q.sinks {
+		a.deliver(s, e)
+	}
+}
+
+// deliver sends the event to the downstream appender or adds it to the
+// pending batch.
+func (a *Appender) deliver(s *sink, e *gol.LoggingEvent) {
+	if a.isDisabled(s) {
+		s.drop(DropDisabled, e.Level)
+		return
+	}
+	if s.batcher == nil {
+		if err := gol.SafeAppend(s.appender, e); err != nil {
+			s.drop(DropPanic, e.Level)
+			a.failed(s, err)
+			return
+		}
+		s.failures = 0
+		s.delivered(1)
+		return
+	}
+	s.batch = append(s.batch, e)
+	if len(s.batch) >= a.batchSize {
+		a.deliverBatch(s)
+	}
+}
+
+// flush sends all pending data in the channel to writer until the drain
+// context is done, then discards the rest.
+func (a *Appender) flush(q *queue) {
+	a.mu.RLock()
+	done := a.drainCtx.Done()
+	a.mu.RUnlock()
+	for {
+		// Done channel has higher priority.
+		select {
+		case <-done:
+			a.discard(q)
+			return
+		default:
+		}
+		select {
+		case e := <-q.c:
+			a.deliverAll(q, e)
+			// Continue reading from the appender.
+		default:
+			// Channel is empty.
+			a.deliverBatches(q)
+			return
+		}
+	}
+}
+
+// flushQueue delivers events pending at the time of calling and flushes the
+// downstream appenders.
+func (a *Appender) flushQueue(q *queue) error {
+loop:
+	for n := len(q.c); n > 0; n-- {
+		select {
+		case e := <-q.c:
+			a.deliverAll(q, e)
+		default:
+			// Removed by DropOldest policy.
+			break loop
+		}
+	}
+	a.deliverBatches(q)
+	var err error
+	for _, s := range q.sinks {
+		if e := gol.FlushAppender(s.appender); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// discard removes all pending events and batches, counting them as timed out.
+func (a *Appender) discard(q *queue) {
+	for _, s := range q.sinks {
+		s.timedOut(len(s.batch))
+		s.clearBatch()
+	}
+	a.discardEvents(q)
+}
+
+// discardEvents removes all pending events in the channel, counting them as
+// timed out.
+func (a *Appender) discardEvents(q *queue) {
+	n := 0
+	for {
+		select {
+		case <-q.c:
+			n++
+		default:
+			q.timedOut(n)
+			return
+		}
+	}
+}
+
+// failed records a failure of the downstream appender and disables it if it
+// fails too many times.
+func (a *Appender) failed(s *sink, err error) {
+	gol.ReportError(err)
+	s.failures++
+	if a.maxFailures > 0 && s.failures >= a.maxFailures {
+		s.failures = 0
+		s.disabledUntil = time.Now().Add(a.failureBackoff)
+	}
+}
+
+// isDisabled checks if the downstream appender is disabled.
+func (a *Appender) isDisabled(s *sink) bool {
+	if s.disabledUntil.IsZero() {
+		return false
+	}
+	if time.Now().Before(s.disabledUntil) {
+		return true
+	}
+	s.disabledUntil = time.Time{}
+	return false
+}
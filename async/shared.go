@@ -0,0 +1,170 @@
+package async
+
+import (
+	"sync/atomic"
+
+	"github.com/goburrow/gol"
+)
+
+// NewSharedAppender returns an asynchronous appender with one channel of
+// bufSize events for all appenders. A dispatcher forwards events from the
+// channel to at most workers go routines, each delivering events to a fixed
+// subset of appenders, so events are delivered to every appender in the order
+// they are appended. Unlike NewAppenderWithBufSize, memory and number of go
+// routines do not grow with number of appenders, but a slow appender delays
+// the others.
+func NewSharedAppender(bufSize, workers int, appenders ...gol.Appender) *Appender {
+	if workers > len(appenders) {
+		workers = len(appenders)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	a := &Appender{
+		drainTimeout: defaultDrainTimeout,
+		bufSize:      bufSize,
+		shared:       true,
+	}
+	shared := newQueue(bufSize)
+	a.queues = []*queue{shared}
+	a.workers = make([]*queue, workers)
+	for i := range a.workers {
+		// Workers receive one event at a time so the only buffer is the
+		// shared channel.
+		a.workers[i] = newQueue(0)
+		a.workers[i].upstream = shared
+	}
+	a.sinks = make([]*sink, len(appenders))
+	for i := range appenders {
+		w := a.workers[i%workers]
+		s := newSink(appenders[i], shared, w)
+		a.sinks[i] = s
+		w.sinks = append(w.sinks, s)
+	}
+	shared.sinks = a.sinks
+	return a
+}
+
+// dispatch forwards events from the shared queue to workers until finish is
+// closed, then closes workerFinish once all pending events are forwarded.
+func (a *Appender) dispatch(q *queue, finish, workerFinish chan struct{}) {
+	defer a.wg.Done()
+	defer close(workerFinish)
+
+	for {
+		// Finish has higher priority.
+		select {
+		case <-finish:
+			a.drain(q)
+			return
+		default:
+		}
+		select {
+		case <-finish:
+			a.drain(q)
+			return
+		case e := <-q.c:
+			a.forward(e, nil)
+		case fn := <-q.ctrl:
+			fn()
+		}
+	}
+}
+
+// forward sends the event to all workers. It returns the workers which have
+// not received the event when done is closed.
+func (a *Appender) forward(e *gol.LoggingEvent, done <-chan struct{}) []*queue {
+	// Workers do not deliver pending batches while the event is coming.
+	for _, w := range a.workers {
+		atomic.AddInt32(&w.incoming, 1)
+	}
+	for i, w := range a.workers {
+		select {
+		case w.c <- e:
+		case <-done:
+			for _, w := range a.workers[i:] {
+				atomic.AddInt32(&w.incoming, -1)
+			}
+			return a.workers[i:]
+		}
+	}
+	return nil
+}
+
+// drain forwards pending events in the shared queue to workers until the
+// drain context is done, then discards the rest.
+func (a *Appender) drain(q *queue) {
+	a.mu.RLock()
+	done := a.drainCtx.Done()
+	a.mu.RUnlock()
+	for {
+		// Done channel has higher priority.
+		select {
+		case <-done:
+			a.mu.RLock()
+			a.discardEvents(q)
+			a.mu.RUnlock()
+			return
+		default:
+		}
+		select {
+		case e := <-q.c:
+			if workers := a.forward(e, done); len(workers) > 0 {
+				a.mu.RLock()
+				for _, s := range q.sinks {
+					if containsQueue(workers, s.worker) {
+						s.timedOut(1)
+					}
+				}
+				a.discardEvents(q)
+				a.mu.RUnlock()
+				return
+			}
+		default:
+			// Channel is empty.
+			return
+		}
+	}
+}
+
+func containsQueue(queues []*queue, q *queue) bool {
+	for _, v := range queues {
+		if v == q {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardPending forwards events pending at the time of calling to workers.
+func (a *Appender) forwardPending(q *queue) {
+	for n := len(q.c); n > 0; n-- {
+		select {
+		case e := <-q.c:
+			a.forward(e, nil)
+		default:
+			// Removed by DropOldest policy.
+			return
+		}
+	}
+}
+
+// flushShared forwards events pending at the time of calling to workers, then
+// flushes all workers.
+func (a *Appender) flushShared(q *queue) error {
+	a.forwardPending(q)
+	dones := make([]<-chan error, len(a.workers))
+	for i, w := range a.workers {
+		w := w
+		dones[i] = w.do(func() error {
+			return a.flushQueue(w)
+		})
+	}
+	var err error
+	for _, done := range dones {
+		if e := <-done; e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
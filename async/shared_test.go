@@ -0,0 +1,131 @@
+package async
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/goburrow/gol"
+)
+
+func TestSharedAppenderOrder(t *testing.T) {
+	writers := make([]*lockedWriter, 5)
+	appenders := make([]gol.Appender, len(writers))
+	for i := range writers {
+		writers[i] = &lockedWriter{}
+		appenders[i] = gol.NewAppender(writers[i])
+	}
+	appender := NewSharedAppender(4, 2, appenders...)
+	if len(appender.workers) != 2 || len(appender.workers[0].sinks) != 3 || len(appender.workers[1].sinks) != 2 {
+		t.Fatalf("unexpected workers: %+v", appender.workers)
+	}
+	appender.Start()
+	for i := 0; i < 20; i++ {
+		appender.Append(newEvent(gol.Info, fmt.Sprint(i)))
+	}
+	appender.Stop()
+	for i, w := range writers {
+		if len(w.s) != 20 {
+			t.Fatalf("unexpected messages %d: %v", i, w.s)
+		}
+		for j, s := range w.s {
+			if s[len(s)-len(fmt.Sprint(j))-1:] != fmt.Sprint(j)+"\n" {
+				t.Fatalf("unexpected order %d: %v", i, w.s)
+			}
+		}
+	}
+	stats := appender.Stats()
+	if stats.Enqueued != 100 || stats.Delivered != 100 || len(stats.Queues) != 5 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestSharedAppenderWorkers(t *testing.T) {
+	appender := NewSharedAppender(1, 10, gol.NewAppender(&lockedWriter{}))
+	if len(appender.workers) != 1 {
+		t.Fatalf("unexpected workers: %d", len(appender.workers))
+	}
+	appender = NewSharedAppender(1, 0, gol.NewAppender(&lockedWriter{}), gol.NewAppender(&lockedWriter{}))
+	if len(appender.workers) != 1 || len(appender.workers[0].sinks) != 2 {
+		t.Fatalf("unexpected workers: %+v", appender.workers)
+	}
+}
+
+func TestSharedAppenderOverflow(t *testing.T) {
+	w := newBlockingWriter()
+	appender := NewSharedAppender(2, 1, gol.NewAppender(w))
+	appender.SetOverflowPolicy(DropNewest, 0)
+	events := []*gol.LoggingEvent{
+		newEvent(gol.Info, "1"),
+		newEvent(gol.Info, "2"),
+		newEvent(gol.Info, "3"),
+		newEvent(gol.Info, "4"),
+	}
+	// The dispatcher holds one event while the worker is blocked.
+	appender.Start()
+	appender.Append(events[0])
+	<-w.entered
+	appender.Append(events[1])
+	for appender.Stats().Queues[0].Depth != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	for _, e := range events[2:] {
+		appender.Append(e)
+	}
+	appender.Append(newEvent(gol.Info, "5"))
+	close(w.release)
+	appender.Stop()
+	if w.messages() != "1,2,3,4" {
+		t.Fatalf("unexpected messages: %v", w.messages())
+	}
+	if n := appender.Stats().Dropped[DropOverflow][gol.Info]; n != 1 {
+		t.Fatalf("unexpected dropped: %d", n)
+	}
+}
+
+func TestSharedAppenderFlush(t *testing.T) {
+	w1 := &slowWriter{2 * time.Millisecond, nil}
+	w2 := &slowWriter{1 * time.Millisecond, nil}
+	d1 := &flushAppender{Appender: gol.NewAppender(w1)}
+	d2 := &flushAppender{Appender: gol.NewAppender(w2)}
+	appender := NewSharedAppender(10, 2, d1, d2)
+	appender.Start()
+	defer appender.Stop()
+	for i := 0; i < 5; i++ {
+		appender.Append(newEvent(gol.Info, "run"))
+	}
+	if err := appender.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(w1.s) != 5 || len(w2.s) != 5 || d1.flushes != 1 || d2.flushes != 1 {
+		t.Fatalf("unexpected messages: %v %v", w1.s, w2.s)
+	}
+}
+
+func TestSharedAppenderDrainTimeout(t *testing.T) {
+	w := newBlockingWriter()
+	appender := NewSharedAppender(4, 1, gol.NewAppender(w))
+	appender.Start()
+	appender.Append(newEvent(gol.Info, "1"))
+	<-w.entered
+	appender.Append(newEvent(gol.Info, "2"))
+	for appender.Stats().Queues[0].Depth != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	appender.Append(newEvent(gol.Info, "3"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := appender.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	close(w.release)
+	appender.Start()
+	appender.Stop()
+	// The dispatcher was already forwarding the second event.
+	stats := appender.Stats()
+	if stats.TimedOut != 1 || stats.Delivered != 2 || w.messages() != "1,2" {
+		t.Fatalf("unexpected stats: %+v, messages: %v", stats, w.messages())
+	}
+}
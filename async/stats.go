@@ -0,0 +1,187 @@
+package async
+
+import (
+	"sync/atomic"
+
+	"github.com/goburrow/gol"
+)
+
+// DropReason is the reason an event is dropped.
+type DropReason int
+
+// Drop reasons
+const (
+	// DropOverflow is when the channel is full and the overflow policy is
+	// DropNewest or DropOldest.
+	DropOverflow DropReason = iota
+	// DropTimeout is when the channel is still full after block timeout.
+	DropTimeout
+	// DropThreshold is when the channel exceeds discard threshold.
+	DropThreshold
+	// DropStopped is when the appender is not started or Shutdown timed out
+	// while the event was blocked.
+	DropStopped
+	// DropPanic is when the downstream appender panics.
+	DropPanic
+	// DropDisabled is when the downstream appender is disabled after
+	// repeated failures.
+	DropDisabled
+
+	numDropReasons
+)
+
+var dropReasonStrings = [...]string{
+	DropOverflow:  "overflow",
+	DropTimeout:   "timeout",
+	DropThreshold: "threshold",
+	DropStopped:   "stopped",
+	DropPanic:     "panic",
+	DropDisabled:  "disabled",
+}
+
+// String returns the text for the drop reason.
+func (r DropReason) String() string {
+	if r >= 0 && r < numDropReasons {
+		return dropReasonStrings[r]
+	}
+	return ""
+}
+
+const numLevels = int(gol.Off) + 1
+
+// counters are statistics of a sink, accessed atomically.
+type counters struct {
+	enqueuedCount  uint64
+	deliveredCount uint64
+	timedOutCount  uint64
+	droppedCount   [numDropReasons][numLevels]uint64
+	highWater      int64
+}
+
+// enqueued counts an event accepted to the queue for all its sinks.
+func (q *queue) enqueued() {
+	depth := len(q.c)
+	for _, s := range q.sinks {
+		atomic.AddUint64(&s.enqueuedCount, 1)
+		s.updateHighWater(depth)
+	}
+}
+
+// drop counts an event dropped from the queue for all its sinks.
+func (q *queue) drop(reason DropReason, level gol.Level) {
+	for _, s := range q.sinks {
+		s.drop(reason, level)
+	}
+}
+
+// timedOut counts events discarded from the queue for all its sinks.
+func (q *queue) timedOut(n int) {
+	for _, s := range q.sinks {
+		s.timedOut(n)
+	}
+}
+
+func (c *counters) delivered(n int) {
+	atomic.AddUint64(&c.deliveredCount, uint64(n))
+}
+
+func (c *counters) timedOut(n int) {
+	atomic.AddUint64(&c.timedOutCount, uint64(n))
+}
+
+func (c *counters) drop(reason DropReason, level gol.Level) {
+	if level < 0 || int(level) >= numLevels {
+		level = gol.Uninitialized
+	}
+	atomic.AddUint64(&c.droppedCount[reason][level], 1)
+}
+
+// updateHighWater records depth if it is higher than the current high-water
+// mark.
+func (c *counters) updateHighWater(depth int) {
+	for {
+		old := atomic.LoadInt64(&c.highWater)
+		if int64(depth) <= old || atomic.CompareAndSwapInt64(&c.highWater, old, int64(depth)) {
+			return
+		}
+	}
+}
+
+// Stats contains statistics of an Appender. Events are counted for each
+// downstream appender, e.g. an event appended to an Appender with two
+// downstream appenders is counted twice.
+type Stats struct {
+	// Enqueued is number of events accepted to channels.
+	Enqueued uint64
+	// Delivered is number of events sent to downstream appenders.
+	Delivered uint64
+	// TimedOut is number of events left in channels when draining timed out.
+	TimedOut uint64
+	// Dropped is number of events dropped by reason and level.
+	Dropped map[DropReason]map[gol.Level]uint64
+	// Queues contains statistics of each downstream appender in the same
+	// order as they are given to the Appender.
+	Queues []QueueStats
+}
+
+// TotalDropped returns number of dropped events of all reasons and levels.
+func (s *Stats) TotalDropped() uint64 {
+	var n uint64
+	for _, levels := range s.Dropped {
+		for _, count := range levels {
+			n += count
+		}
+	}
+	return n
+}
+
+// QueueStats contains statistics of a downstream appender channel.
+// In shared mode, Depth and HighWater are of the shared channel.
+type QueueStats struct {
+	// Depth is current number of pending events.
+	Depth int
+	// HighWater is maximum number of pending events observed.
+	HighWater int
+	Enqueued  uint64
+	Delivered uint64
+	TimedOut  uint64
+	Dropped   uint64
+}
+
+// Stats returns current statistics of the appender.
+func (a *Appender) Stats() Stats {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	s := Stats{
+		Dropped: make(map[DropReason]map[gol.Level]uint64),
+		Queues:  make([]QueueStats, len(a.sinks)),
+	}
+	for i, q := range a.sinks {
+		qs := &s.Queues[i]
+		qs.Depth = len(q.source.c)
+		qs.HighWater = int(atomic.LoadInt64(&q.highWater))
+		qs.Enqueued = atomic.LoadUint64(&q.enqueuedCount)
+		qs.Delivered = atomic.LoadUint64(&q.deliveredCount)
+		qs.TimedOut = atomic.LoadUint64(&q.timedOutCount)
+		for reason := range q.droppedCount {
+			for level := range q.droppedCount[reason] {
+				n := atomic.LoadUint64(&q.droppedCount[reason][level])
+				if n == 0 {
+					continue
+				}
+				levels := s.Dropped[DropReason(reason)]
+				if levels == nil {
+					levels = make(map[gol.Level]uint64)
+					s.Dropped[DropReason(reason)] = levels
+				}
+				levels[gol.Level(level)] += n
+				qs.Dropped += n
+			}
+		}
+		s.Enqueued += qs.Enqueued
+		s.Delivered += qs.Delivered
+		s.TimedOut += qs.TimedOut
+	}
+	return s
+}
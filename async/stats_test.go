@@ -0,0 +1,93 @@
+package async
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goburrow/gol"
+)
+
+func TestAppenderStats(t *testing.T) {
+	w := newBlockingWriter()
+	appender := NewAppenderWithBufSize(2, gol.NewAppender(w))
+	appender.SetOverflowPolicy(DropNewest, 0)
+
+	appender.Append(newEvent(gol.Info, "0"))
+	events := []*gol.LoggingEvent{
+		newEvent(gol.Info, "1"),
+		newEvent(gol.Info, "2"),
+		newEvent(gol.Debug, "3"),
+		newEvent(gol.Error, "4"),
+	}
+	testOverflow(t, appender, w, events, "1,2,3")
+
+	stats := appender.Stats()
+	if stats.Enqueued != 3 || stats.Delivered != 3 || stats.TimedOut != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if stats.TotalDropped() != 2 || stats.Dropped[DropStopped][gol.Info] != 1 ||
+		stats.Dropped[DropOverflow][gol.Error] != 1 {
+		t.Fatalf("unexpected dropped: %+v", stats.Dropped)
+	}
+	if len(stats.Queues) != 1 {
+		t.Fatalf("unexpected queues: %+v", stats.Queues)
+	}
+	q := stats.Queues[0]
+	if q.Depth != 0 || q.HighWater != 2 || q.Enqueued != 3 || q.Delivered != 3 || q.Dropped != 2 {
+		t.Fatalf("unexpected queue stats: %+v", q)
+	}
+	if DropThreshold.String() != "threshold" {
+		t.Fatalf("unexpected string: %v", DropThreshold)
+	}
+}
+
+func TestAppenderDrainTimeout(t *testing.T) {
+	w := newBlockingWriter()
+	appender := NewAppenderWithBufSize(2, gol.NewAppender(w))
+	appender.Start()
+	appender.Append(newEvent(gol.Info, "1"))
+	<-w.entered
+	appender.Append(newEvent(gol.Info, "2"))
+	appender.Append(newEvent(gol.Info, "3"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := appender.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	close(w.release)
+	// Start waits for the previous run.
+	appender.Start()
+	appender.Stop()
+	stats := appender.Stats()
+	if stats.TimedOut != 2 || stats.Delivered != 1 || w.messages() != "1" {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestAppenderDropReporter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := gol.New("async", nil)
+	logger.SetLevel(gol.Info)
+	logger.SetAppender(gol.NewAppender(&buf))
+
+	appender := NewAppenderWithBufSize(1, gol.NewAppender(&bytes.Buffer{}))
+	appender.SetDropReporter(logger, time.Hour)
+	appender.Append(newEvent(gol.Info, "1"))
+	appender.Append(newEvent(gol.Info, "2"))
+	appender.Start()
+	appender.Stop()
+	if !strings.HasSuffix(buf.String(), "async: 2 events dropped by asynchronous appender\n") {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+	// No more dropped events.
+	buf.Reset()
+	appender.Start()
+	appender.Stop()
+	if buf.String() != "" {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+}
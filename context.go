@@ -0,0 +1,162 @@
+package gol
+
+import (
+	"context"
+)
+
+// ContextLogger is a Logger bound to values extracted from a context.Context,
+// additional fields and markers.
+// It implements Logger interface.
+type ContextLogger struct {
+	logger *DefaultLogger
+
+	traceID string
+	spanID  string
+	// fields must not be modified once assigned as it is shared with
+	// logging events.
+	fields []Field
+	// markers must not be modified once assigned as well.
+	markers []Marker
+}
+
+var _ Logger = (*ContextLogger)(nil)
+
+// WithContext returns a Logger which adds trace and span IDs extracted from
+// ctx to its logging events.
+func (logger *DefaultLogger) WithContext(ctx context.Context) *ContextLogger {
+	c := &ContextLogger{
+		logger: logger,
+	}
+	c.setContext(ctx)
+	return c
+}
+
+// WithContext returns a copy of this logger with trace and span IDs
+// extracted from ctx.
+func (c *ContextLogger) WithContext(ctx context.Context) *ContextLogger {
+	n := *c
+	n.setContext(ctx)
+	return &n
+}
+
+// With returns a Logger which adds the given field to its logging events.
+func (logger *DefaultLogger) With(key, value string) *ContextLogger {
+	c := &ContextLogger{
+		logger: logger,
+	}
+	return c.With(key, value)
+}
+
+// With returns a copy of this logger with the given field added.
+func (c *ContextLogger) With(key, value string) *ContextLogger {
+	n := *c
+	n.fields = make([]Field, len(c.fields), len(c.fields)+1)
+	copy(n.fields, c.fields)
+	n.fields = append(n.fields, Field{Key: key, Value: value})
+	return &n
+}
+
+// WithMarker returns a Logger which adds the given markers to its logging
+// events.
+func (logger *DefaultLogger) WithMarker(markers ...Marker) *ContextLogger {
+	c := &ContextLogger{
+		logger: logger,
+	}
+	return c.WithMarker(markers...)
+}
+
+// WithMarker returns a copy of this logger with the given markers added.
+func (c *ContextLogger) WithMarker(markers ...Marker) *ContextLogger {
+	n := *c
+	n.markers = make([]Marker, len(c.markers), len(c.markers)+len(markers))
+	copy(n.markers, c.markers)
+	n.markers = append(n.markers, markers...)
+	return &n
+}
+
+func (c *ContextLogger) setContext(ctx context.Context) {
+	extractor := c.logger.TraceExtractor()
+	if extractor == nil || ctx == nil {
+		return
+	}
+	traceID, spanID, ok := extractor.Extract(ctx)
+	if ok {
+		c.traceID = traceID
+		c.spanID = spanID
+	}
+}
+
+// TraceID returns the trace ID bound to this logger.
+func (c *ContextLogger) TraceID() string {
+	return c.traceID
+}
+
+// SpanID returns the span ID bound to this logger.
+func (c *ContextLogger) SpanID() string {
+	return c.spanID
+}
+
+// Fields returns fields bound to this logger.
+func (c *ContextLogger) Fields() []Field {
+	return c.fields
+}
+
+// Markers returns markers bound to this logger.
+func (c *ContextLogger) Markers() []Marker {
+	return c.markers
+}
+
+// Tracef logs message at Trace level.
+func (c *ContextLogger) Tracef(format string, args ...interface{}) {
+	c.logger.printf(Trace, format, args, c)
+}
+
+// TraceEnabled checks if Trace level is enabled.
+func (c *ContextLogger) TraceEnabled() bool {
+	return c.logger.loggable(Trace)
+}
+
+// Debugf logs message at Debug level.
+func (c *ContextLogger) Debugf(format string, args ...interface{}) {
+	c.logger.printf(Debug, format, args, c)
+}
+
+// DebugEnabled checks if Debug level is enabled.
+func (c *ContextLogger) DebugEnabled() bool {
+	return c.logger.loggable(Debug)
+}
+
+// Infof logs message at Info level.
+func (c *ContextLogger) Infof(format string, args ...interface{}) {
+	c.logger.printf(Info, format, args, c)
+}
+
+// InfoEnabled checks if Info level is enabled.
+func (c *ContextLogger) InfoEnabled() bool {
+	return c.logger.loggable(Info)
+}
+
+// Warnf logs message at Warning level.
+func (c *ContextLogger) Warnf(format string, args ...interface{}) {
+	c.logger.printf(Warn, format, args, c)
+}
+
+// WarnEnabled checks if Warning level is enabled.
+func (c *ContextLogger) WarnEnabled() bool {
+	return c.logger.loggable(Warn)
+}
+
+// Errorf logs message at Error level.
+func (c *ContextLogger) Errorf(format string, args ...interface{}) {
+	c.logger.printf(Error, format, args, c)
+}
+
+// ErrorEnabled checks if Error level is enabled.
+func (c *ContextLogger) ErrorEnabled() bool {
+	return c.logger.loggable(Error)
+}
+
+// Printf logs message at the given level.
+func (c *ContextLogger) Printf(level Level, format string, args []interface{}) {
+	c.logger.printf(level, format, args, c)
+}
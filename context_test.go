@@ -0,0 +1,80 @@
+package gol
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+type traceKey struct{}
+
+func TestContextLogger(t *testing.T) {
+	var buf bytes.Buffer
+	factory := NewFactory(&buf)
+
+	ctx := ContextWithTraceParent(context.Background(),
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	logger := factory.GetLogger("app").(*DefaultLogger).WithContext(ctx)
+	assertEquals(t, "4bf92f3577b34da6a3ce929d0e0e4736", logger.TraceID())
+	assertEquals(t, "00f067aa0ba902b7", logger.SpanID())
+
+	logger.Debugf("debug")
+	logger.Infof("info %d", 1)
+	assertEquals(t, false, logger.DebugEnabled())
+	assertEquals(t, true, logger.InfoEnabled())
+	if !strings.HasSuffix(buf.String(),
+		"] app [trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7]: info 1\n") {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+
+	// Events from the logger itself does not have trace context.
+	buf.Reset()
+	factory.GetLogger("app").Infof("info")
+	if !strings.HasSuffix(buf.String(), "] app: info\n") {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+}
+
+func TestContextLoggerWithExtractor(t *testing.T) {
+	var buf bytes.Buffer
+	root := New(RootLoggerName, nil)
+	root.SetLevel(Info)
+	root.SetAppender(NewAppender(&buf))
+
+	logger := New("app", root)
+	logger.SetTraceExtractor(TraceExtractorFunc(func(ctx context.Context) (string, string, bool) {
+		s, ok := ctx.Value(traceKey{}).(string)
+		return s, "span", ok
+	}))
+	ctx := context.WithValue(context.Background(), traceKey{}, "trace")
+	c := logger.WithContext(context.Background())
+	assertEquals(t, "", c.TraceID())
+	c = c.WithContext(ctx)
+	assertEquals(t, "trace", c.TraceID())
+	assertEquals(t, "span", c.SpanID())
+
+	c.Warnf("warn")
+	if !strings.HasSuffix(buf.String(), "] app [trace_id=trace span_id=span]: warn\n") {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+	// No extractor
+	c = root.WithContext(ctx)
+	assertEquals(t, "", c.TraceID())
+}
+
+func TestContextLoggerWith(t *testing.T) {
+	var buf bytes.Buffer
+	factory := NewFactory(&buf)
+	logger := factory.GetLogger("app").(*DefaultLogger)
+
+	a := logger.With("a", "1")
+	b := a.With("b", "2")
+	c := a.With("c", "3")
+	if len(a.Fields()) != 1 || len(b.Fields()) != 2 || len(c.Fields()) != 2 {
+		t.Fatalf("unexpected fields: %v %v %v", a.Fields(), b.Fields(), c.Fields())
+	}
+	b.Infof("b")
+	c.Errorf("c")
+	assertContains(t, buf.String(), "] app [a=1 b=2]: b\n", "] app [a=1 c=3]: c\n")
+}
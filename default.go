@@ -4,8 +4,14 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 const packageSeparator = '/'
@@ -53,10 +59,44 @@ type LoggingEvent struct {
 	Level Level
 	// Time is when the logging happens.
 	Time time.Time
+	// TraceID and SpanID identify the trace the event belongs to, if any.
+	TraceID string
+	SpanID  string
+	// Fields are key-value pairs bound to the logger.
+	Fields []Field
+	// Markers are tags bound to the logger.
+	Markers []Marker
+	// Format is the format template of the message.
+	Format string
 
 	Message bytes.Buffer
 }
 
+// Clone returns a copy of the event which is owned by the caller.
+// Appenders which keep the event after Append returns, e.g. asynchronous
+// appenders, must use a copy as the original event may be reused.
+func (e *LoggingEvent) Clone() *LoggingEvent {
+	c := &LoggingEvent{
+		Name:    e.Name,
+		Level:   e.Level,
+		Time:    e.Time,
+		TraceID: e.TraceID,
+		SpanID:  e.SpanID,
+		// Fields and markers are never modified once assigned.
+		Fields:  e.Fields,
+		Markers: e.Markers,
+		Format:  e.Format,
+	}
+	c.Message.Write(e.Message.Bytes())
+	return c
+}
+
+// Field is a key-value pair attached to logging events.
+type Field struct {
+	Key   string
+	Value string
+}
+
 var eventPool = sync.Pool{}
 
 func newLoggingEvent() *LoggingEvent {
@@ -68,6 +108,11 @@ func newLoggingEvent() *LoggingEvent {
 }
 
 func releaseLoggingEvent(e *LoggingEvent) {
+	e.TraceID = ""
+	e.SpanID = ""
+	e.Fields = nil
+	e.Markers = nil
+	e.Format = ""
 	e.Message.Reset()
 	eventPool.Put(e)
 }
@@ -77,6 +122,46 @@ type Appender interface {
 	Append(*LoggingEvent)
 }
 
+// Flusher is implemented by appenders which can push all events appended so
+// far to their targets.
+type Flusher interface {
+	Flush() error
+}
+
+// syncer is implemented by os.File.
+type syncer interface {
+	Sync() error
+}
+
+// FlushAppender flushes the appender if it implements Flusher.
+func FlushAppender(appender Appender) error {
+	if f, ok := appender.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// FlushWriter flushes the writer if it implements Flusher or has Sync method
+// like os.File. Files which are not regular, e.g. os.Stdout on a terminal or
+// pipe, are not synced as they do not support it.
+func FlushWriter(w io.Writer) error {
+	switch w := w.(type) {
+	case Flusher:
+		return w.Flush()
+	case syncer:
+		if f, ok := w.(*os.File); ok && !isRegularFile(f) {
+			return nil
+		}
+		return w.Sync()
+	}
+	return nil
+}
+
+func isRegularFile(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode().IsRegular()
+}
+
 // DefaultAppender implements Appender interface.
 type DefaultAppender struct {
 	timeLayout string
@@ -119,6 +204,8 @@ func (appender *DefaultAppender) Append(event *LoggingEvent) {
 	// Logger name
 	buf.WriteByte(' ')
 	buf.WriteString(event.Name)
+	// Markers, trace context and fields
+	AppendEventContext(buf, event)
 	buf.WriteByte(':')
 
 	// Logging message in the end
@@ -132,6 +219,74 @@ func (appender *DefaultAppender) Append(event *LoggingEvent) {
 	}
 }
 
+// AppendEventContext writes markers, trace and span IDs and fields of the
+// event to buf as " [markers=a,b trace_id=x span_id=y key=value]".
+// Field values containing spaces, quotes, '=' or ']' are quoted.
+// Nothing is written if the event has none of them.
+func AppendEventContext(buf *bytes.Buffer, event *LoggingEvent) {
+	if len(event.Markers) == 0 && event.TraceID == "" && len(event.Fields) == 0 {
+		return
+	}
+	buf.WriteString(" [")
+	if len(event.Markers) > 0 {
+		buf.WriteString("markers=")
+		for i, m := range event.Markers {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(string(m))
+		}
+	}
+	if event.TraceID != "" {
+		if len(event.Markers) > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString("trace_id=")
+		buf.WriteString(event.TraceID)
+		buf.WriteString(" span_id=")
+		buf.WriteString(event.SpanID)
+	}
+	for i, f := range event.Fields {
+		if i > 0 || event.TraceID != "" || len(event.Markers) > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(f.Key)
+		buf.WriteByte('=')
+		writeFieldValue(buf, f.Value)
+	}
+	buf.WriteByte(']')
+}
+
+// writeFieldValue writes the value quoted if it could be mistaken for other
+// parts of the event context.
+func writeFieldValue(buf *bytes.Buffer, value string) {
+	if needsQuote(value) {
+		buf.WriteString(strconv.Quote(value))
+	} else {
+		buf.WriteString(value)
+	}
+}
+
+func needsQuote(s string) bool {
+	if !utf8.ValidString(s) {
+		return true
+	}
+	for _, c := range s {
+		if c == ' ' || c == ']' || c == '=' || c == '"' || !unicode.IsPrint(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// Flush flushes the target writer if it is buffered.
+func (appender *DefaultAppender) Flush() error {
+	if appender.target == nil {
+		return nil
+	}
+	return FlushWriter(appender.target)
+}
+
 // DefaultLogger implements Logger interface.
 type DefaultLogger struct {
 	name  string
@@ -139,6 +294,8 @@ type DefaultLogger struct {
 
 	appender Appender
 
+	traceExtractor TraceExtractor
+
 	parent *DefaultLogger
 }
 
@@ -237,13 +394,34 @@ func (logger *DefaultLogger) SetAppender(appender Appender) {
 	logger.appender = appender
 }
 
+// TraceExtractor returns trace extractor of this logger or parent if not set.
+func (logger *DefaultLogger) TraceExtractor() TraceExtractor {
+	for logger != nil {
+		if logger.traceExtractor != nil {
+			return logger.traceExtractor
+		}
+		logger = logger.parent
+	}
+	return nil
+}
+
+// SetTraceExtractor changes trace extractor of this logger.
+func (logger *DefaultLogger) SetTraceExtractor(extractor TraceExtractor) {
+	logger.traceExtractor = extractor
+}
+
 // loggable checks if the given logging level is enabled within this logger.
 func (logger *DefaultLogger) loggable(level Level) bool {
 	return level >= logger.Level()
 }
 
-// log performs logging with given parameters.
+// Printf performs logging with given parameters.
 func (logger *DefaultLogger) Printf(level Level, format string, args []interface{}) {
+	logger.printf(level, format, args, nil)
+}
+
+// printf logs message with values bound to c if it is not nil.
+func (logger *DefaultLogger) printf(level Level, format string, args []interface{}, c *ContextLogger) {
 	if !logger.loggable(level) {
 		return
 	}
@@ -257,6 +435,13 @@ func (logger *DefaultLogger) Printf(level Level, format string, args []interface
 	event.Time = time.Now()
 	event.Name = logger.name
 	event.Level = level
+	event.Format = format
+	if c != nil {
+		event.TraceID = c.traceID
+		event.SpanID = c.spanID
+		event.Fields = c.fields
+		event.Markers = c.markers
+	}
 	fmt.Fprintf(&event.Message, format, args...)
 
 	appender.Append(event)
@@ -275,6 +460,7 @@ func NewFactory(writer io.Writer) *DefaultFactory {
 	rootLogger := New(RootLoggerName, nil)
 	rootLogger.SetLevel(Info)
 	rootLogger.SetAppender(NewAppender(writer))
+	rootLogger.SetTraceExtractor(TraceParentExtractor)
 
 	return &DefaultFactory{
 		root: rootLogger,
@@ -300,6 +486,76 @@ func (factory *DefaultFactory) GetLogger(name string) Logger {
 	return logger
 }
 
+// Flush flushes appenders of all loggers created by this factory.
+// It returns the first error encountered.
+func (factory *DefaultFactory) Flush() error {
+	var err error
+	for _, appender := range factory.appenders() {
+		if e := FlushAppender(appender); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Start starts appenders of all loggers created by this factory and their
+// dependencies implementing Lifecycle. See StartAll.
+func (factory *DefaultFactory) Start() error {
+	return StartAll(factory.components()...)
+}
+
+// Stop stops appenders of all loggers created by this factory and their
+// dependencies in the reverse order of Start. See StopAll.
+func (factory *DefaultFactory) Stop() error {
+	return StopAll(factory.components()...)
+}
+
+func (factory *DefaultFactory) components() []interface{} {
+	appenders := factory.appenders()
+	components := make([]interface{}, len(appenders))
+	for i := range appenders {
+		components[i] = appenders[i]
+	}
+	return components
+}
+
+// appenders returns distinct appenders of all loggers ordered by logger name.
+func (factory *DefaultFactory) appenders() []Appender {
+	factory.mu.RLock()
+	defer factory.mu.RUnlock()
+
+	names := make([]string, 0, len(factory.loggers))
+	for name := range factory.loggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var appenders []Appender
+	seen := make(map[Appender]bool, len(factory.loggers))
+	for _, name := range names {
+		appender := factory.loggers[name].appender
+		if appender == nil {
+			continue
+		}
+		// Only pointers are deduplicated as other values may not be valid
+		// map keys, e.g. structs holding slices.
+		if isPointer(appender) {
+			if seen[appender] {
+				continue
+			}
+			seen[appender] = true
+		}
+		appenders = append(appenders, appender)
+	}
+	return appenders
+}
+
+// isPointer returns true if v is a pointer, which is always a valid map key.
+// reflect.Type.Comparable is not enough as an interface field of a struct may
+// hold a value which is not comparable.
+func isPointer(v interface{}) bool {
+	return reflect.TypeOf(v).Kind() == reflect.Ptr
+}
+
 // getParent returns parent logger for given logger.
 func (factory *DefaultFactory) getParent(name string) *DefaultLogger {
 	parent := factory.root
@@ -260,3 +260,28 @@ func TestHierarchyWithRootLogger(t *testing.T) {
 	assertEquals(t, 2, len(factory.loggers))
 	assertEquals(t, root, a.parent)
 }
+
+func TestLoggingEventClone(t *testing.T) {
+	event := newLoggingEvent()
+	event.Name = "name"
+	event.Level = Warn
+	event.Time = time.Now()
+	event.TraceID = "trace"
+	event.SpanID = "span"
+	event.Fields = []Field{{Key: "k", Value: "v"}}
+	event.Markers = []Marker{"AUDIT"}
+	event.Format = "%s"
+	event.Message.WriteString("message")
+
+	c := event.Clone()
+	releaseLoggingEvent(event)
+	assertEquals(t, "name", c.Name)
+	assertEquals(t, Warn, c.Level)
+	assertEquals(t, "trace", c.TraceID)
+	assertEquals(t, "span", c.SpanID)
+	assertEquals(t, "v", c.Fields[0].Value)
+	assertEquals(t, Marker("AUDIT"), c.Markers[0])
+	assertEquals(t, 0, len(event.Markers))
+	assertEquals(t, "%s", c.Format)
+	assertEquals(t, "message", c.Message.String())
+}
@@ -18,7 +18,12 @@ type Appender struct {
 	appender gol.Appender
 }
 
-var _ (gol.Appender) = (*Appender)(nil)
+var (
+	_ (gol.Appender)  = (*Appender)(nil)
+	_ (gol.Flusher)   = (*Appender)(nil)
+	_ (gol.Lifecycle) = (*Appender)(nil)
+	_ (gol.Dependent) = (*Appender)(nil)
+)
 
 // NewAppender allocates and returns a new Appender.
 // Calling Start is only needed for catching errors.
@@ -41,6 +46,14 @@ func (a *Appender) Append(event *gol.LoggingEvent) {
 	}
 }
 
+// Flush commits written logging events to stable storage.
+func (a *Appender) Flush() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.file.Sync()
+}
+
 // SetTriggeringPolicy changes the triggering policy of this appender.
 func (a *Appender) SetTriggeringPolicy(policy rotation.TriggeringPolicy) {
 	a.mu.Lock()
@@ -55,6 +68,15 @@ func (a *Appender) SetRollingPolicy(policy rotation.RollingPolicy) {
 	a.mu.Unlock()
 }
 
+// Dependencies returns the triggering and rolling policies, which are started
+// before the file is opened, e.g. TimeTriggeringPolicy.
+func (a *Appender) Dependencies() []interface{} {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return []interface{}{a.file.TriggeringPolicy(), a.file.RollingPolicy()}
+}
+
 // Start opens the log file.
 func (a *Appender) Start() error {
 	a.mu.Lock()
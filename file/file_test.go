@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/goburrow/gol"
+	"github.com/goburrow/gol/file/rotation"
 )
 
 func TestFile(t *testing.T) {
@@ -34,6 +35,9 @@ func TestFile(t *testing.T) {
 	}
 	event.Message.WriteString("message")
 	appender.Append(event)
+	if err = appender.Flush(); err != nil {
+		t.Fatal(err)
+	}
 
 	data, err := ioutil.ReadFile(file)
 	if err != nil {
@@ -80,3 +84,31 @@ func TestExistedFile(t *testing.T) {
 		t.Fatalf("unexpected content: %s", content)
 	}
 }
+
+func TestLifecycle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	policy := rotation.NewTimeTriggeringPolicy()
+	appender := NewAppender(filepath.Join(dir, "test.log"))
+	appender.SetTriggeringPolicy(policy)
+	deps := appender.Dependencies()
+	if len(deps) != 2 || deps[0] != policy {
+		t.Fatalf("unexpected dependencies: %#v", deps)
+	}
+	if err = gol.StartAll(appender); err != nil {
+		t.Fatal(err)
+	}
+	if !appender.file.IsOpenned() {
+		t.Fatal("file is not opened")
+	}
+	if err = gol.StopAll(appender); err != nil {
+		t.Fatal(err)
+	}
+	if appender.file.IsOpenned() {
+		t.Fatal("file is not closed")
+	}
+}
@@ -97,6 +97,10 @@ func (p *TimeTriggeringPolicy) Start() error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	if p.timer != nil {
+		// Already started.
+		return nil
+	}
 	p.finish = make(chan struct{})
 	p.startTimer()
 	return nil
@@ -122,7 +126,7 @@ func (p *TimeTriggeringPolicy) startTimer() {
 	tmr := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 1E3, time.Local)
 	p.timer = time.NewTimer(tmr.Sub(now))
 
-	go p.checkTriggering()
+	go p.checkTriggering(p.timer, p.finish)
 }
 
 // TriggerTime returns the time trigger event was raised.
@@ -134,15 +138,18 @@ func (p *TimeTriggeringPolicy) TriggerTime() time.Time {
 }
 
 // checkTriggering must be called in a go routine.
-func (p *TimeTriggeringPolicy) checkTriggering() {
-	timer := p.timer
+func (p *TimeTriggeringPolicy) checkTriggering(timer *time.Timer, finish chan struct{}) {
 	select {
-	case <-p.finish:
+	case <-finish:
 		return
 	case tm := <-timer.C:
 		p.mu.Lock()
 		defer p.mu.Unlock()
 
+		if p.timer != timer {
+			// Stopped after the timer fired.
+			return
+		}
 		p.isTriggering = true
 		p.triggerTime = tm
 		p.startTimer()
@@ -45,6 +45,16 @@ func (f *File) Write(b []byte) (int, error) {
 	return f.file.Write(b)
 }
 
+// TriggeringPolicy returns the triggering policy of the file.
+func (f *File) TriggeringPolicy() TriggeringPolicy {
+	return f.triggeringPolicy
+}
+
+// RollingPolicy returns the rolling policy of the file.
+func (f *File) RollingPolicy() RollingPolicy {
+	return f.rollingPolicy
+}
+
 // Open opens the file.
 func (f *File) Open() error {
 	return f.OpenFile(openFlag, openMode)
@@ -75,6 +85,14 @@ func (f *File) Close() error {
 	return err
 }
 
+// Sync commits the current contents of the file to stable storage.
+func (f *File) Sync() error {
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Sync()
+}
+
 // SetTriggeringPolicy sets a new TriggeringPolicy.
 func (f *File) SetTriggeringPolicy(p TriggeringPolicy) {
 	f.triggeringPolicy = p
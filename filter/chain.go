@@ -0,0 +1,97 @@
+package filter
+
+import (
+	"github.com/goburrow/gol"
+)
+
+// Decision is the result of a Filter.
+type Decision int
+
+// Filter decisions
+const (
+	// Neutral passes the event to the next filter.
+	Neutral Decision = iota
+	// Accept appends the event without checking the remaining filters.
+	Accept
+	// Deny drops the event without checking the remaining filters.
+	Deny
+)
+
+var decisionStrings = [...]string{
+	Neutral: "neutral",
+	Accept:  "accept",
+	Deny:    "deny",
+}
+
+// String returns the text for the decision.
+func (d Decision) String() string {
+	if d >= 0 && int(d) < len(decisionStrings) {
+		return decisionStrings[d]
+	}
+	return ""
+}
+
+// Filter decides whether a logging event is appended.
+type Filter interface {
+	Decide(*gol.LoggingEvent) Decision
+}
+
+// FilterFunc is an adapter to allow the use of ordinary functions as Filter.
+type FilterFunc func(*gol.LoggingEvent) Decision
+
+// Decide calls f(e).
+func (f FilterFunc) Decide(e *gol.LoggingEvent) Decision {
+	return f(e)
+}
+
+// Chain is a Filter evaluating its filters in order. The first decision
+// other than Neutral is returned. Events rewritten by filters in a nested
+// Chain, e.g. DowngradeFilter, are seen by the following filters.
+type Chain []Filter
+
+var (
+	_ Filter   = (Chain)(nil)
+	_ rewriter = (Chain)(nil)
+)
+
+// Decide returns the first decision other than Neutral or Neutral if all
+// filters are neutral. Use Rewrite to get the event changed by the filters.
+func (c Chain) Decide(e *gol.LoggingEvent) Decision {
+	_, d := c.apply(e)
+	return d
+}
+
+// Rewrite returns the event rewritten by the filters evaluated until the
+// decision. The given event is not changed.
+func (c Chain) Rewrite(e *gol.LoggingEvent) *gol.LoggingEvent {
+	e, _ = c.apply(e)
+	return e
+}
+
+// rewriter is implemented by filters which change events, e.g.
+// DowngradeFilter. Rewrite must return a modified copy instead of changing
+// the event as it may be shared with other appenders.
+type rewriter interface {
+	Rewrite(*gol.LoggingEvent) *gol.LoggingEvent
+}
+
+// apply returns the event rewritten by the filters before the decision.
+func (c Chain) apply(e *gol.LoggingEvent) (*gol.LoggingEvent, Decision) {
+	for _, f := range c {
+		var d Decision
+		switch v := f.(type) {
+		case Chain:
+			// Evaluate nested filters once for both event and decision.
+			e, d = v.apply(e)
+		case rewriter:
+			e = v.Rewrite(e)
+			d = f.Decide(e)
+		default:
+			d = f.Decide(e)
+		}
+		if d != Neutral {
+			return e, d
+		}
+	}
+	return e, Neutral
+}
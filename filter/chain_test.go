@@ -0,0 +1,197 @@
+package filter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/goburrow/gol"
+)
+
+func newEvent(name string, level gol.Level, msg string) *gol.LoggingEvent {
+	e := &gol.LoggingEvent{
+		Name:  name,
+		Level: level,
+	}
+	e.Message.WriteString(msg)
+	return e
+}
+
+func TestFilters(t *testing.T) {
+	nameFilter, err := NameFilter(Accept, Deny, "app/*/db", "lib")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		filter   Filter
+		event    *gol.LoggingEvent
+		expected Decision
+	}{
+		{LevelFilter(gol.Warn, Accept, Deny), newEvent("", gol.Warn, ""), Accept},
+		{LevelFilter(gol.Warn, Accept, Deny), newEvent("", gol.Error, ""), Deny},
+		{ThresholdFilter(gol.Info), newEvent("", gol.Debug, ""), Deny},
+		{ThresholdFilter(gol.Info), newEvent("", gol.Info, ""), Neutral},
+		{LevelRangeFilter(gol.Debug, gol.Info, Accept, Neutral), newEvent("", gol.Debug, ""), Accept},
+		{LevelRangeFilter(gol.Debug, gol.Info, Accept, Neutral), newEvent("", gol.Warn, ""), Neutral},
+		{nameFilter, newEvent("app/user/db/pool", gol.Info, ""), Accept},
+		{nameFilter, newEvent("lib", gol.Info, ""), Accept},
+		{nameFilter, newEvent("library", gol.Info, ""), Deny},
+		{MessageFilter("reset", Deny, Neutral), newEvent("", gol.Info, "connection reset by peer"), Deny},
+		{MessageFilter("reset", Deny, Neutral), newEvent("", gol.Info, "connected"), Neutral},
+	}
+	for i, test := range tests {
+		if d := test.filter.Decide(test.event); d != test.expected {
+			t.Fatalf("%d: unexpected decision: %v, expected: %v", i, d, test.expected)
+		}
+	}
+	if _, err := NameFilter(Accept, Deny, "^("); err == nil {
+		t.Fatal("error expected")
+	}
+}
+
+func TestChain(t *testing.T) {
+	chain := Chain{
+		MessageFilter("noise", Deny, Neutral),
+		LevelFilter(gol.Error, Accept, Neutral),
+		ThresholdFilter(gol.Warn),
+	}
+	tests := []struct {
+		event    *gol.LoggingEvent
+		expected Decision
+	}{
+		{newEvent("", gol.Error, "noise"), Deny},
+		{newEvent("", gol.Error, "message"), Accept},
+		{newEvent("", gol.Info, "message"), Deny},
+		{newEvent("", gol.Warn, "message"), Neutral},
+	}
+	for i, test := range tests {
+		if d := chain.Decide(test.event); d != test.expected {
+			t.Fatalf("%d: unexpected decision: %v, expected: %v", i, d, test.expected)
+		}
+	}
+	if Accept.String() != "accept" || Decision(10).String() != "" {
+		t.Fatalf("unexpected string: %v", Accept)
+	}
+}
+
+func TestAppenderFilters(t *testing.T) {
+	var buf bytes.Buffer
+	appender := NewAppender(gol.NewAppender(&buf))
+	appender.SetThreshold(gol.Warn)
+	appender.SetExcludes("app")
+	appender.SetFilters(
+		MessageFilter("noise", Deny, Neutral),
+		LevelFilter(gol.Debug, Accept, Neutral),
+	)
+	appender.Append(newEvent("app", gol.Debug, "debug"))
+	appender.Append(newEvent("lib", gol.Error, "noise"))
+	appender.Append(newEvent("app", gol.Error, "error"))
+	appender.Append(newEvent("lib", gol.Info, "info"))
+	appender.Append(newEvent("lib", gol.Warn, "warn"))
+	msgs := buf.String()
+	if !bytes.Contains(buf.Bytes(), []byte("app: debug")) || !bytes.Contains(buf.Bytes(), []byte("lib: warn")) ||
+		bytes.Count(buf.Bytes(), []byte("\n")) != 2 {
+		t.Fatalf("unexpected messages: %s", msgs)
+	}
+}
+
+func TestMessageRegexFilter(t *testing.T) {
+	f, err := MessageRegexFilter(`^connection (reset|refused)`, Deny, Neutral)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := f.Decide(newEvent("", gol.Warn, "connection refused")); d != Deny {
+		t.Fatalf("unexpected decision: %v", d)
+	}
+	if d := f.Decide(newEvent("", gol.Warn, "no connection reset")); d != Neutral {
+		t.Fatalf("unexpected decision: %v", d)
+	}
+	if _, err = MessageRegexFilter("(", Deny, Neutral); err == nil {
+		t.Fatal("error expected")
+	}
+}
+
+func TestDowngradeFilter(t *testing.T) {
+	var buf bytes.Buffer
+	appender := NewAppender(gol.NewAppender(&buf))
+	appender.SetThreshold(gol.Info)
+	appender.SetFilters(
+		DowngradeFilter(MessageFilter("connection reset", Accept, Neutral), gol.Debug),
+		DowngradeFilter(MessageFilter("slow", Accept, Neutral), gol.Info),
+	)
+	event := newEvent("lib", gol.Warn, "connection reset by peer")
+	appender.Append(event)
+	if buf.Len() != 0 {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+	// The original event is not changed.
+	if event.Level != gol.Warn {
+		t.Fatalf("unexpected level: %v", event.Level)
+	}
+	appender.Append(newEvent("lib", gol.Error, "slow query"))
+	if !bytes.HasPrefix(buf.Bytes(), []byte("INFO ")) {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+	buf.Reset()
+	// Events already at lower level are not changed.
+	appender.Append(newEvent("lib", gol.Debug, "slow query"))
+	appender.Append(newEvent("lib", gol.Warn, "message"))
+	if !bytes.HasPrefix(buf.Bytes(), []byte("WARN ")) || bytes.Count(buf.Bytes(), []byte("\n")) != 1 {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+}
+
+func TestDowngradeFilterNested(t *testing.T) {
+	var buf bytes.Buffer
+	appender := NewAppender(gol.NewAppender(&buf))
+	appender.SetThreshold(gol.Info)
+	appender.SetFilters(Chain{
+		DowngradeFilter(MessageFilter("noisy", Accept, Neutral), gol.Debug),
+	})
+	appender.Append(newEvent("lib", gol.Warn, "noisy warning"))
+	if buf.Len() != 0 {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+
+	c := Chain{
+		Chain{DowngradeFilter(MessageFilter("noisy", Accept, Neutral), gol.Debug)},
+		ThresholdFilter(gol.Info),
+	}
+	event := newEvent("lib", gol.Warn, "noisy warning")
+	if d := c.Decide(event); d != Deny {
+		t.Fatalf("unexpected decision: %v", d)
+	}
+	if e := c.Rewrite(event); e.Level != gol.Debug || event.Level != gol.Warn {
+		t.Fatalf("unexpected levels: %v %v", e.Level, event.Level)
+	}
+}
+
+// teeAppender sends events to all appenders.
+type teeAppender []gol.Appender
+
+func (t teeAppender) Append(e *gol.LoggingEvent) {
+	for _, a := range t {
+		a.Append(e)
+	}
+}
+
+func TestMarkerFilter(t *testing.T) {
+	var audit, other bytes.Buffer
+	auditAppender := NewAppender(gol.NewAppender(&audit))
+	auditAppender.SetFilters(MarkerFilter(Accept, Deny, "AUDIT", "SECURITY"))
+	otherAppender := NewAppender(gol.NewAppender(&other))
+	otherAppender.SetFilters(MarkerFilter(Deny, Neutral, "AUDIT"))
+
+	logger := gol.New("app/db", nil)
+	logger.SetLevel(gol.Info)
+	logger.SetAppender(teeAppender{auditAppender, otherAppender})
+	logger.WithMarker("AUDIT").Infof("audit")
+	logger.WithMarker("SECURITY/AUTH").Infof("login")
+	logger.Infof("query")
+	if audit.String() == "" || bytes.Count(audit.Bytes(), []byte("\n")) != 2 ||
+		!bytes.Contains(audit.Bytes(), []byte("app/db [markers=SECURITY/AUTH]: login")) {
+		t.Fatalf("unexpected audit messages: %s", audit.String())
+	}
+	if bytes.Count(other.Bytes(), []byte("\n")) != 2 || bytes.Contains(other.Bytes(), []byte("audit")) {
+		t.Fatalf("unexpected messages: %s", other.String())
+	}
+}
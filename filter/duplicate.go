@@ -0,0 +1,115 @@
+package filter
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/goburrow/gol"
+	"github.com/goburrow/gol/internal/sampler"
+)
+
+// duplicateKey identifies repeated events.
+type duplicateKey struct {
+	name    string
+	level   gol.Level
+	message string
+}
+
+// DuplicateAppender suppresses repeated events which have the same logger
+// name, level and message (or message format). The first event in a window is
+// passed to the underlying appender and the repeats are suppressed. When the
+// window ends, an event "last message repeated N times" is sent if there are
+// repeats. Once started, ended windows are checked periodically so summaries
+// are sent even when the repeats stop. Pending summaries are also sent on
+// Flush and Stop.
+type DuplicateAppender struct {
+	appender gol.Appender
+	sampler  *sampler.Sampler
+
+	mu       sync.Mutex
+	byFormat bool
+}
+
+var (
+	_ gol.Appender  = (*DuplicateAppender)(nil)
+	_ gol.Flusher   = (*DuplicateAppender)(nil)
+	_ gol.Lifecycle = (*DuplicateAppender)(nil)
+	_ gol.Dependent = (*DuplicateAppender)(nil)
+)
+
+// NewDuplicateAppender allocates and returns a new DuplicateAppender.
+func NewDuplicateAppender(a gol.Appender, window time.Duration) *DuplicateAppender {
+	return &DuplicateAppender{
+		appender: a,
+		sampler:  sampler.New(a, window, duplicateSummary),
+	}
+}
+
+// SetCompareFormat sets whether message format template is compared instead
+// of the formatted message, e.g. to suppress the same error with different
+// arguments.
+func (a *DuplicateAppender) SetCompareFormat(byFormat bool) {
+	a.mu.Lock()
+	a.byFormat = byFormat
+	a.mu.Unlock()
+}
+
+// Suppressed returns total number of events suppressed by this appender.
+func (a *DuplicateAppender) Suppressed() uint64 {
+	return a.sampler.Dropped()
+}
+
+// Append sends the event to the underlying appender unless it is a repeat.
+func (a *DuplicateAppender) Append(e *gol.LoggingEvent) {
+	k := duplicateKey{name: e.Name, level: e.Level}
+	a.mu.Lock()
+	byFormat := a.byFormat
+	a.mu.Unlock()
+	if byFormat {
+		k.message = e.Format
+	} else {
+		k.message = e.Message.String()
+	}
+	a.sampler.Append(k, e, isFirst)
+}
+
+// Flush sends summaries of pending repeats and flushes the underlying
+// appender.
+func (a *DuplicateAppender) Flush() error {
+	return a.sampler.Flush()
+}
+
+// Start starts sending summaries of ended windows periodically.
+// The underlying appender is not started.
+func (a *DuplicateAppender) Start() error {
+	return a.sampler.Start()
+}
+
+// Stop stops sending summaries periodically and sends pending ones.
+// The underlying appender is not stopped.
+func (a *DuplicateAppender) Stop() error {
+	return a.sampler.Stop()
+}
+
+// Dependencies returns the underlying appender.
+func (a *DuplicateAppender) Dependencies() []interface{} {
+	return []interface{}{a.appender}
+}
+
+// isFirst returns true for the first event in a window.
+func isFirst(count uint64) bool {
+	return count == 1
+}
+
+// duplicateSummary returns an event reporting number of repeats.
+func duplicateSummary(k interface{}, repeated uint64) *gol.LoggingEvent {
+	dk := k.(duplicateKey)
+	e := &gol.LoggingEvent{
+		Name:   dk.name,
+		Level:  dk.level,
+		Format: "last message repeated %d times",
+	}
+	fmt.Fprintf(&e.Message, e.Format, repeated)
+	return e
+}
@@ -0,0 +1,111 @@
+package filter
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goburrow/gol"
+)
+
+func TestDuplicateAppender(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2015, time.April, 3, 2, 1, 0, 0, time.UTC)
+
+	appender := NewDuplicateAppender(gol.NewAppender(&buf), time.Second)
+	appender.sampler.SetClock(func() time.Time {
+		return now
+	})
+	for i := 0; i < 5; i++ {
+		appender.Append(newEvent("app", gol.Error, "connection refused"))
+	}
+	appender.Append(newEvent("app", gol.Error, "timeout"))
+	appender.Append(newEvent("app", gol.Warn, "connection refused"))
+	appender.Append(newEvent("db", gol.Error, "connection refused"))
+	if strings.Count(buf.String(), "\n") != 4 || appender.Suppressed() != 4 {
+		t.Fatalf("unexpected messages: %s", buf.String())
+	}
+
+	// Next window
+	buf.Reset()
+	now = now.Add(time.Second)
+	appender.Append(newEvent("app", gol.Error, "connection refused"))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "app: last message repeated 4 times") ||
+		!strings.HasPrefix(lines[0], "ERROR [2015-04-03T02:01:01.000Z]") ||
+		!strings.HasSuffix(lines[1], "app: connection refused") {
+		t.Fatalf("unexpected messages: %s", buf.String())
+	}
+	// Ended windows are removed.
+	if appender.sampler.Len() != 1 {
+		t.Fatalf("unexpected duplicates: %d", appender.sampler.Len())
+	}
+}
+
+func TestDuplicateAppenderFlush(t *testing.T) {
+	var buf bytes.Buffer
+	appender := NewDuplicateAppender(gol.NewAppender(&buf), time.Hour)
+	appender.SetCompareFormat(true)
+	logger := gol.New("app", nil)
+	logger.SetLevel(gol.Info)
+	logger.SetAppender(appender)
+	for i := 0; i < 3; i++ {
+		logger.Errorf("error %d", i)
+	}
+	if err := appender.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "app: error 0") ||
+		!strings.HasSuffix(lines[1], "app: last message repeated 2 times") {
+		t.Fatalf("unexpected messages: %s", buf.String())
+	}
+	// No more summaries.
+	buf.Reset()
+	appender.Flush()
+	logger.Errorf("error %d", 3)
+	if !strings.HasSuffix(buf.String(), "app: error 3\n") {
+		t.Fatalf("unexpected messages: %s", buf.String())
+	}
+}
+
+// lockedBuffer is a bytes.Buffer safe for concurrent use.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestDuplicateAppenderStartStop(t *testing.T) {
+	var buf lockedBuffer
+	appender := NewDuplicateAppender(gol.NewAppender(&buf), 10*time.Millisecond)
+	if err := appender.Start(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		appender.Append(newEvent("app", gol.Error, "connection refused"))
+	}
+	// Summary is sent without new events.
+	for i := 0; i < 100 && !strings.Contains(buf.String(), "repeated"); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !strings.HasSuffix(buf.String(), "app: last message repeated 4 times\n") {
+		t.Fatalf("unexpected messages: %s", buf.String())
+	}
+	if err := appender.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
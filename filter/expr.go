@@ -0,0 +1,397 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/goburrow/gol"
+)
+
+// SyntaxError is returned when an expression can not be compiled.
+type SyntaxError struct {
+	// Pos is the byte offset in the expression where the error occurs.
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("filter: syntax error at position %d: %s", e.Pos, e.Msg)
+}
+
+// ExpressionFilter returns a Filter which matches events satisfying expr,
+// e.g.
+//
+//	level >= WARN && logger =~ "^app/" && field.user_id != ""
+//
+// Properties are level, logger (or name), message, time and field.<key>,
+// which is empty if the event does not have the field. A property is
+// compared with a literal using ==, !=, <, <=, > and >=, or a regular
+// expression using =~ and !~ for text properties. Levels are written as their
+// names (case insensitive), texts are double-quoted strings and time is a
+// RFC 3339 string. Conditions are combined with &&, || and !, and grouped
+// with parentheses.
+// The expression is compiled once so matching does not need parsing.
+func ExpressionFilter(expr string, onMatch, onMismatch Decision) (Filter, error) {
+	p := &parser{lexer: lexer{input: expr}}
+	p.next()
+	match, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %s", p.tok)
+	}
+	return FilterFunc(func(e *gol.LoggingEvent) Decision {
+		return decide(match(e), onMatch, onMismatch)
+	}), nil
+}
+
+// matcher is a compiled condition.
+type matcher func(*gol.LoggingEvent) bool
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+	tokAnd
+	tokOr
+	tokNot
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+type lexer struct {
+	input string
+	pos   int
+}
+
+// operators are sorted so that longer ones are matched first.
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "!~", "<", ">", "!", "(", ")"}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.input) && isSpace(l.input[l.pos]) {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos >= len(l.input) {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+	c := l.input[l.pos]
+	switch {
+	case c == '"':
+		return l.readString()
+	case isIdentChar(c):
+		for l.pos < len(l.input) && isIdentChar(l.input[l.pos]) {
+			l.pos++
+		}
+		return token{kind: tokIdent, text: l.input[start:l.pos], pos: start}, nil
+	}
+	for _, op := range operators {
+		if strings.HasPrefix(l.input[l.pos:], op) {
+			l.pos += len(op)
+			t := token{kind: tokOp, text: op, pos: start}
+			switch op {
+			case "&&":
+				t.kind = tokAnd
+			case "||":
+				t.kind = tokOr
+			case "!":
+				t.kind = tokNot
+			case "(":
+				t.kind = tokLParen
+			case ")":
+				t.kind = tokRParen
+			}
+			return t, nil
+		}
+	}
+	r, _ := utf8.DecodeRuneInString(l.input[l.pos:])
+	return token{}, &SyntaxError{Pos: start, Msg: fmt.Sprintf("unexpected character %q", r)}
+}
+
+func (l *lexer) readString() (token, error) {
+	start := l.pos
+	l.pos++
+	for l.pos < len(l.input) {
+		switch l.input[l.pos] {
+		case '\\':
+			l.pos += 2
+			continue
+		case '"':
+			l.pos++
+			s, err := strconv.Unquote(l.input[start:l.pos])
+			if err != nil {
+				return token{}, &SyntaxError{Pos: start, Msg: "invalid string " + l.input[start:l.pos]}
+			}
+			return token{kind: tokString, text: s, pos: start}, nil
+		}
+		l.pos++
+	}
+	return token{}, &SyntaxError{Pos: start, Msg: "unterminated string"}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isIdentChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '.' || c == '-'
+}
+
+type parser struct {
+	lexer lexer
+	tok   token
+	err   error
+}
+
+func (p *parser) next() {
+	if p.err != nil {
+		return
+	}
+	p.tok, p.err = p.lexer.next()
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Pos: p.tok.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// parseOr parses: and { "||" and }
+func (p *parser) parseOr() (matcher, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(e *gol.LoggingEvent) bool {
+			return l(e) || right(e)
+		}
+	}
+	return left, nil
+}
+
+// parseAnd parses: unary { "&&" unary }
+func (p *parser) parseAnd() (matcher, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(e *gol.LoggingEvent) bool {
+			return l(e) && right(e)
+		}
+	}
+	return left, nil
+}
+
+// parseUnary parses: "!" unary | "(" or ")" | comparison
+func (p *parser) parseUnary() (matcher, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	switch p.tok.kind {
+	case tokNot:
+		p.next()
+		m, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(e *gol.LoggingEvent) bool {
+			return !m(e)
+		}, nil
+	case tokLParen:
+		p.next()
+		m, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.err != nil {
+			return nil, p.err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, p.errorf("expected \")\", found %s", p.tok)
+		}
+		p.next()
+		return m, nil
+	case tokIdent:
+		return p.parseComparison()
+	}
+	return nil, p.errorf("expected condition, found %s", p.tok)
+}
+
+// parseComparison parses: property operator literal
+func (p *parser) parseComparison() (matcher, error) {
+	property := p.tok
+	p.next()
+	if p.err != nil {
+		return nil, p.err
+	}
+	if p.tok.kind != tokOp {
+		return nil, p.errorf("expected operator after %s, found %s", property, p.tok)
+	}
+	op := p.tok
+	p.next()
+	if p.err != nil {
+		return nil, p.err
+	}
+	value := p.tok
+	if value.kind != tokString && value.kind != tokIdent {
+		return nil, p.errorf("expected value after %s, found %s", op, value)
+	}
+	p.next()
+
+	name := property.text
+	switch {
+	case name == "level":
+		return compileLevel(op, value)
+	case name == "logger" || name == "name":
+		return compileText(op, value, func(e *gol.LoggingEvent) string {
+			return e.Name
+		})
+	case name == "message":
+		return compileText(op, value, func(e *gol.LoggingEvent) string {
+			return e.Message.String()
+		})
+	case name == "time":
+		return compileTime(op, value)
+	case strings.HasPrefix(name, "field.") && len(name) > len("field."):
+		key := name[len("field."):]
+		return compileText(op, value, func(e *gol.LoggingEvent) string {
+			for _, f := range e.Fields {
+				if f.Key == key {
+					return f.Value
+				}
+			}
+			return ""
+		})
+	}
+	return nil, &SyntaxError{Pos: property.pos, Msg: fmt.Sprintf("unknown property %s", property)}
+}
+
+func compileLevel(op, value token) (matcher, error) {
+	if value.kind != tokIdent {
+		return nil, &SyntaxError{Pos: value.pos, Msg: fmt.Sprintf("expected level name, found %s", value)}
+	}
+	level, ok := parseLevel(value.text)
+	if !ok {
+		return nil, &SyntaxError{Pos: value.pos, Msg: fmt.Sprintf("unknown level %s", value)}
+	}
+	cmp, err := compareOp(op)
+	if err != nil {
+		return nil, err
+	}
+	return func(e *gol.LoggingEvent) bool {
+		return cmp(int(e.Level) - int(level))
+	}, nil
+}
+
+func parseLevel(s string) (gol.Level, bool) {
+	s = strings.ToUpper(s)
+	for level := gol.All; level <= gol.Off; level++ {
+		if gol.LevelString(level) == s {
+			return level, true
+		}
+	}
+	return 0, false
+}
+
+func compileText(op, value token, get func(*gol.LoggingEvent) string) (matcher, error) {
+	if value.kind != tokString {
+		return nil, &SyntaxError{Pos: value.pos, Msg: fmt.Sprintf("expected string, found %s", value)}
+	}
+	if op.text == "=~" || op.text == "!~" {
+		re, err := regexp.Compile(value.text)
+		if err != nil {
+			return nil, &SyntaxError{Pos: value.pos, Msg: err.Error()}
+		}
+		negate := op.text == "!~"
+		return func(e *gol.LoggingEvent) bool {
+			return re.MatchString(get(e)) != negate
+		}, nil
+	}
+	cmp, err := compareOp(op)
+	if err != nil {
+		return nil, err
+	}
+	s := value.text
+	return func(e *gol.LoggingEvent) bool {
+		return cmp(strings.Compare(get(e), s))
+	}, nil
+}
+
+func compileTime(op, value token) (matcher, error) {
+	if value.kind != tokString {
+		return nil, &SyntaxError{Pos: value.pos, Msg: fmt.Sprintf("expected time string, found %s", value)}
+	}
+	t, err := time.Parse(time.RFC3339, value.text)
+	if err != nil {
+		return nil, &SyntaxError{Pos: value.pos, Msg: err.Error()}
+	}
+	cmp, err := compareOp(op)
+	if err != nil {
+		return nil, err
+	}
+	return func(e *gol.LoggingEvent) bool {
+		switch {
+		case e.Time.Before(t):
+			return cmp(-1)
+		case e.Time.After(t):
+			return cmp(1)
+		}
+		return cmp(0)
+	}, nil
+}
+
+// compareOp returns a function checking result of a comparison against op.
+func compareOp(op token) (func(int) bool, error) {
+	switch op.text {
+	case "==":
+		return func(c int) bool { return c == 0 }, nil
+	case "!=":
+		return func(c int) bool { return c != 0 }, nil
+	case "<":
+		return func(c int) bool { return c < 0 }, nil
+	case "<=":
+		return func(c int) bool { return c <= 0 }, nil
+	case ">":
+		return func(c int) bool { return c > 0 }, nil
+	case ">=":
+		return func(c int) bool { return c >= 0 }, nil
+	}
+	return nil, &SyntaxError{Pos: op.pos, Msg: fmt.Sprintf("operator %s is not supported", op)}
+}
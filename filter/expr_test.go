@@ -0,0 +1,72 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goburrow/gol"
+)
+
+func TestExpressionFilter(t *testing.T) {
+	e := newEvent("app/db", gol.Warn, "connection reset")
+	e.Time = time.Date(2015, time.April, 3, 2, 1, 0, 0, time.UTC)
+	e.Fields = []gol.Field{{Key: "user_id", Value: "42"}}
+
+	tests := []struct {
+		expr     string
+		expected bool
+	}{
+		{`level >= WARN && logger =~ "app/.*" && field.user_id != ""`, true},
+		{`level>=warn`, true},
+		{`level > WARN`, false},
+		{`level == warn && !(name == "app")`, true},
+		{`name == "app" || message =~ "^connection"`, true},
+		{`message !~ "reset"`, false},
+		{`field.request_id == ""`, true},
+		{`field.user_id < "5"`, true},
+		{`time >= "2015-04-03T02:00:00Z" && time < "2015-04-03T03:00:00+01:00"`, false},
+		{`time == "2015-04-03T12:01:00+10:00"`, true},
+		{`(level == ERROR || level == WARN) && logger != "app/db/pool"`, true},
+		{`!level < INFO`, true},
+		{`message == "connection \"reset\""`, false},
+	}
+	for _, test := range tests {
+		f, err := ExpressionFilter(test.expr, Accept, Deny)
+		if err != nil {
+			t.Fatalf("%s: %v", test.expr, err)
+		}
+		if d := f.Decide(e); (d == Accept) != test.expected {
+			t.Fatalf("%s: unexpected decision: %v", test.expr, d)
+		}
+	}
+}
+
+func TestExpressionFilterErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		err  string
+	}{
+		{``, `filter: syntax error at position 0: expected condition, found end of expression`},
+		{`level`, `filter: syntax error at position 5: expected operator after "level", found end of expression`},
+		{`level >= NOTICE`, `filter: syntax error at position 9: unknown level "NOTICE"`},
+		{`level =~ "WARN"`, `filter: syntax error at position 9: expected level name, found "WARN"`},
+		{`level =~ WARN`, `filter: syntax error at position 6: operator "=~" is not supported`},
+		{`host == "a"`, `filter: syntax error at position 0: unknown property "host"`},
+		{`name == app`, `filter: syntax error at position 8: expected string, found "app"`},
+		{`name == "app`, `filter: syntax error at position 8: unterminated string`},
+		{`name =~ "("`, "filter: syntax error at position 8: error parsing regexp: missing closing ): `(`"},
+		{`(level == WARN`, `filter: syntax error at position 14: expected ")", found end of expression`},
+		{`level == WARN name == "a"`, `filter: syntax error at position 14: unexpected "name"`},
+		{`level == WARN & name == "a"`, `filter: syntax error at position 14: unexpected character '&'`},
+		{`time > "yesterday"`, `filter: syntax error at position 7: parsing time "yesterday" as "2006-01-02T15:04:05Z07:00": cannot parse "yesterday" as "2006"`},
+	}
+	for _, test := range tests {
+		_, err := ExpressionFilter(test.expr, Accept, Deny)
+		if err == nil || err.Error() != test.err {
+			t.Fatalf("%s: unexpected error: %v, expected: %s", test.expr, err, test.err)
+		}
+		if _, ok := err.(*SyntaxError); !ok {
+			t.Fatalf("%s: unexpected error type: %T", test.expr, err)
+		}
+	}
+}
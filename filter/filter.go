@@ -1,27 +1,47 @@
 /*
-Package filter provides an appender which has filter.
+Package filter provides appenders which filter, suppress duplicate or rate
+limit logging events, and filters which can be composed as a chain.
 */
 package filter
 
 import (
 	"sort"
+	"strings"
 
 	"github.com/goburrow/gol"
 )
 
+// packageSeparator separates logger names in the hierarchy as in
+// gol.DefaultFactory.
+const packageSeparator = '/'
+
 // Appender is an logging appender which supports level threshold, inclusive or
 // exclusive logger name.
 type Appender struct {
 	appender gol.Appender
 
 	threshold gol.Level
+	// thresholds are thresholds of loggers and their descendants overriding
+	// threshold.
+	thresholds map[string]gol.Level
 	// Make sure includes and excludes are sorted as it relies on binary search
 	// to check if the logger is in the list.
 	includes []string
 	excludes []string
+	// includePatterns and excludePatterns are globs or regular expressions.
+	includePatterns []pattern
+	excludePatterns []pattern
+	// cache contains decisions by logger name.
+	cache decisionCache
+	// filters are evaluated before the other checks.
+	filters Chain
 }
 
-var _ (gol.Appender) = (*Appender)(nil)
+var (
+	_ (gol.Appender)  = (*Appender)(nil)
+	_ (gol.Flusher)   = (*Appender)(nil)
+	_ (gol.Dependent) = (*Appender)(nil)
+)
 
 // NewAppender allocates and returns a new Appender
 func NewAppender(a gol.Appender) *Appender {
@@ -32,28 +52,104 @@ func NewAppender(a gol.Appender) *Appender {
 }
 
 // Append only send logging event to the assigned appender only when:
-// - Logging level >= Threshold
+// - Logging level >= Threshold of the logger
 // - Logger name is not in the excludes
 // - Logger name is in the includes
+// Filters set by SetFilters are checked first: Accept appends the event
+// regardless of the other conditions and Deny drops it.
+// An entry in includes or excludes also covers descendants of the logger,
+// e.g. "app/db" covers "app/db/pool". The most specific entry wins, and
+// excludes overrule includes of the same logger.
 func (a *Appender) Append(e *gol.LoggingEvent) {
-	if e.Level < a.threshold {
+	e, decision := a.filters.apply(e)
+	switch decision {
+	case Deny:
 		return
-	}
-	if len(a.excludes) > 0 {
-		idx := sort.SearchStrings(a.excludes, e.Name)
-		if idx < len(a.excludes) && a.excludes[idx] == e.Name {
-			// Excluded
+	case Neutral:
+		if e.Level < a.thresholdOf(e.Name) {
 			return
 		}
-	}
-	if len(a.includes) > 0 {
-		idx := sort.SearchStrings(a.includes, e.Name)
-		if !(idx < len(a.includes) && a.includes[idx] == e.Name) {
-			// Not included
+		if !a.isIncluded(e.Name) {
 			return
 		}
 	}
-	a.appender.Append(e)
+	if err := gol.SafeAppend(a.appender, e); err != nil {
+		gol.ReportError(err)
+	}
+}
+
+// Flush flushes the underlying appender.
+func (a *Appender) Flush() error {
+	return gol.FlushAppender(a.appender)
+}
+
+// Dependencies returns the underlying appender.
+func (a *Appender) Dependencies() []interface{} {
+	return []interface{}{a.appender}
+}
+
+// thresholdOf returns the threshold of the logger or its closest ancestor
+// in thresholds, or the appender threshold if not found.
+func (a *Appender) thresholdOf(name string) gol.Level {
+	if len(a.thresholds) == 0 {
+		return a.threshold
+	}
+	for {
+		if t, ok := a.thresholds[name]; ok {
+			return t
+		}
+		idx := strings.LastIndexByte(name, packageSeparator)
+		if idx < 0 {
+			return a.threshold
+		}
+		name = name[:idx]
+	}
+}
+
+// isIncluded checks the logger name and its ancestors against excludes and
+// includes, from the most specific.
+func (a *Appender) isIncluded(name string) bool {
+	if len(a.excludes) == 0 && len(a.includes) == 0 &&
+		len(a.excludePatterns) == 0 && len(a.includePatterns) == 0 {
+		return true
+	}
+	if included, ok := a.cache.get(name); ok {
+		return included
+	}
+	included := a.decide(name)
+	a.cache.put(name, included)
+	return included
+}
+
+func (a *Appender) decide(name string) bool {
+	for {
+		if contains(a.excludes, name) || matchAny(a.excludePatterns, name) {
+			return false
+		}
+		if contains(a.includes, name) || matchAny(a.includePatterns, name) {
+			return true
+		}
+		idx := strings.LastIndexByte(name, packageSeparator)
+		if idx < 0 {
+			break
+		}
+		name = name[:idx]
+	}
+	// Not included.
+	return len(a.includes) == 0 && len(a.includePatterns) == 0
+}
+
+// contains checks if name is in the sorted list.
+func contains(list []string, name string) bool {
+	idx := sort.SearchStrings(list, name)
+	return idx < len(list) && list[idx] == name
+}
+
+// SetFilters replaces the filter chain of this appender.
+func (a *Appender) SetFilters(filters ...Filter) {
+	c := make(Chain, len(filters))
+	copy(c, filters)
+	a.filters = c
 }
 
 // SetThreshold change logging threshold.
@@ -61,18 +157,58 @@ func (a *Appender) SetThreshold(t gol.Level) {
 	a.threshold = t
 }
 
-// SetIncludes set inclusive logger names.
+// SetLoggerThresholds sets thresholds for loggers and their descendants,
+// overriding the appender threshold. The threshold of the longest matching
+// logger name wins, e.g. with "app" at Warn and "app/payments" at Debug,
+// "app/payments/card" is at Debug and "app/users" is at Warn.
+func (a *Appender) SetLoggerThresholds(thresholds map[string]gol.Level) {
+	t := make(map[string]gol.Level, len(thresholds))
+	for name, level := range thresholds {
+		t[name] = level
+	}
+	a.thresholds = t
+}
+
+// SetIncludes set inclusive logger names and their descendants.
 func (a *Appender) SetIncludes(includes ...string) {
 	in := make([]string, len(includes))
 	copy(in, includes)
 	sort.Strings(in)
 	a.includes = in
+	a.cache.reset()
 }
 
-// SetExcludes set exclusive logger names.
+// SetExcludes set exclusive logger names and their descendants.
 func (a *Appender) SetExcludes(excludes ...string) {
 	ex := make([]string, len(excludes))
 	copy(ex, excludes)
 	sort.Strings(ex)
 	a.excludes = ex
+	a.cache.reset()
+}
+
+// SetIncludePatterns set inclusive logger name patterns. A pattern starting
+// with "^" is a regular expression, e.g. "^vendor/.*$", otherwise it is a
+// glob as in path.Match, e.g. "app/*/db". Like includes, a pattern matching
+// a logger also covers its descendants.
+func (a *Appender) SetIncludePatterns(patterns ...string) error {
+	compiled, err := compilePatterns(patterns)
+	if err != nil {
+		return err
+	}
+	a.includePatterns = compiled
+	a.cache.reset()
+	return nil
+}
+
+// SetExcludePatterns set exclusive logger name patterns.
+// See SetIncludePatterns for the syntax.
+func (a *Appender) SetExcludePatterns(patterns ...string) error {
+	compiled, err := compilePatterns(patterns)
+	if err != nil {
+		return err
+	}
+	a.excludePatterns = compiled
+	a.cache.reset()
+	return nil
 }
@@ -92,3 +92,146 @@ func TestAppenderExcludes(t *testing.T) {
 		t.Fatalf("unexpected message: %#v", msg)
 	}
 }
+
+type panicAppender struct{}
+
+func (panicAppender) Append(*gol.LoggingEvent) {
+	panic("append")
+}
+
+func TestAppenderPanic(t *testing.T) {
+	var errs []error
+	gol.SetErrorHandler(func(err error) {
+		errs = append(errs, err)
+	})
+	defer gol.SetErrorHandler(nil)
+
+	appender := NewAppender(panicAppender{})
+	appender.Append(&gol.LoggingEvent{Level: gol.Info})
+	if len(errs) != 1 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+}
+
+func TestAppenderHierarchy(t *testing.T) {
+	tests := []struct {
+		includes []string
+		excludes []string
+		name     string
+		expected bool
+	}{
+		{nil, []string{"app/db"}, "app/db/pool", false},
+		{nil, []string{"app/db"}, "app/dbx", true},
+		{nil, []string{"app/db"}, "app", true},
+		{[]string{"app"}, nil, "app/db/pool", true},
+		{[]string{"app"}, nil, "application", false},
+		{[]string{"app"}, []string{"app/db"}, "app/db/pool", false},
+		{[]string{"app"}, []string{"app/db"}, "app/http", true},
+		{[]string{"app/db/pool"}, []string{"app/db"}, "app/db/pool/conn", true},
+		{[]string{"app/db/pool"}, []string{"app/db"}, "app/db/query", false},
+		{[]string{"app"}, []string{"app"}, "app/db", false},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		appender := NewAppender(gol.NewAppender(&buf))
+		appender.SetIncludes(test.includes...)
+		appender.SetExcludes(test.excludes...)
+		appender.Append(&gol.LoggingEvent{Name: test.name, Level: gol.Info})
+		if (buf.Len() > 0) != test.expected {
+			t.Fatalf("unexpected result for %+v: %q", test, buf.String())
+		}
+	}
+}
+
+func TestAppenderPatterns(t *testing.T) {
+	tests := []struct {
+		includes []string
+		excludes []string
+		name     string
+		expected bool
+	}{
+		{nil, []string{"app/*/db"}, "app/user/db", false},
+		{nil, []string{"app/*/db"}, "app/user/db/pool", false},
+		{nil, []string{"app/*/db"}, "app/user/cache", true},
+		{nil, []string{"app/*/db"}, "app/a/b/db", true},
+		{nil, []string{"^vendor/.*$"}, "vendor/lib", false},
+		{nil, []string{"^vendor/.*$"}, "vendor", true},
+		{[]string{"^(app|lib)$"}, nil, "lib/x", true},
+		{[]string{"^(app|lib)$"}, nil, "other", false},
+		{[]string{"app/*"}, []string{"app/db?"}, "app/db1", false},
+		{[]string{"app/*"}, []string{"app/db?"}, "app/db", true},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		appender := NewAppender(gol.NewAppender(&buf))
+		if err := appender.SetIncludePatterns(test.includes...); err != nil {
+			t.Fatal(err)
+		}
+		if err := appender.SetExcludePatterns(test.excludes...); err != nil {
+			t.Fatal(err)
+		}
+		// The second event uses cached decision.
+		for i := 0; i < 2; i++ {
+			buf.Reset()
+			appender.Append(&gol.LoggingEvent{Name: test.name, Level: gol.Info})
+			if (buf.Len() > 0) != test.expected {
+				t.Fatalf("unexpected result for %+v: %q", test, buf.String())
+			}
+		}
+	}
+}
+
+func TestAppenderInvalidPatterns(t *testing.T) {
+	appender := NewAppender(gol.NewAppender(&bytes.Buffer{}))
+	if err := appender.SetIncludePatterns("app/[a-"); err == nil {
+		t.Fatal("error expected")
+	}
+	if err := appender.SetExcludePatterns("^app/(db"); err == nil {
+		t.Fatal("error expected")
+	}
+}
+
+func TestAppenderCacheReset(t *testing.T) {
+	var buf bytes.Buffer
+	appender := NewAppender(gol.NewAppender(&buf))
+	appender.SetExcludes("app")
+	event := &gol.LoggingEvent{Name: "app/db", Level: gol.Info}
+	appender.Append(event)
+	appender.SetExcludes()
+	appender.Append(event)
+	if buf.Len() == 0 {
+		t.Fatal("stale decision")
+	}
+}
+
+func TestAppenderLoggerThresholds(t *testing.T) {
+	var buf bytes.Buffer
+	appender := NewAppender(gol.NewAppender(&buf))
+	appender.SetThreshold(gol.Warn)
+	appender.SetLoggerThresholds(map[string]gol.Level{
+		"app":              gol.Error,
+		"app/payments":     gol.Debug,
+		"app/payments/raw": gol.Off,
+	})
+	tests := []struct {
+		name     string
+		level    gol.Level
+		expected bool
+	}{
+		{"app/payments", gol.Debug, true},
+		{"app/payments/card", gol.Debug, true},
+		{"app/payments/card", gol.Trace, false},
+		{"app/payments/raw", gol.Error, false},
+		{"app/paymentsx", gol.Warn, false},
+		{"app/users", gol.Error, true},
+		{"other", gol.Warn, true},
+		{"other", gol.Info, false},
+	}
+	for _, test := range tests {
+		buf.Reset()
+		appender.Append(&gol.LoggingEvent{Name: test.name, Level: test.level})
+		if (buf.Len() > 0) != test.expected {
+			t.Fatalf("unexpected result for %+v: %q", test, buf.String())
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package filter
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+
+	"github.com/goburrow/gol"
+)
+
+// decide returns onMatch if matched or onMismatch otherwise.
+func decide(matched bool, onMatch, onMismatch Decision) Decision {
+	if matched {
+		return onMatch
+	}
+	return onMismatch
+}
+
+// LevelFilter returns a Filter which matches events at exactly the level.
+func LevelFilter(level gol.Level, onMatch, onMismatch Decision) Filter {
+	return FilterFunc(func(e *gol.LoggingEvent) Decision {
+		return decide(e.Level == level, onMatch, onMismatch)
+	})
+}
+
+// ThresholdFilter returns a Filter which denies events below the level and is
+// neutral to the others.
+func ThresholdFilter(level gol.Level) Filter {
+	return FilterFunc(func(e *gol.LoggingEvent) Decision {
+		return decide(e.Level < level, Deny, Neutral)
+	})
+}
+
+// LevelRangeFilter returns a Filter which matches events with level between
+// min and max inclusively.
+func LevelRangeFilter(min, max gol.Level, onMatch, onMismatch Decision) Filter {
+	return FilterFunc(func(e *gol.LoggingEvent) Decision {
+		return decide(e.Level >= min && e.Level <= max, onMatch, onMismatch)
+	})
+}
+
+// NameFilter returns a Filter which matches events of loggers matching any
+// of the patterns, or whose ancestors do. See Appender.SetIncludePatterns for
+// the pattern syntax; a logger name without special characters matches itself.
+func NameFilter(onMatch, onMismatch Decision, patterns ...string) (Filter, error) {
+	compiled, err := compilePatterns(patterns)
+	if err != nil {
+		return nil, err
+	}
+	var cache decisionCache
+	return FilterFunc(func(e *gol.LoggingEvent) Decision {
+		matched, ok := cache.get(e.Name)
+		if !ok {
+			matched = matchHierarchy(compiled, e.Name)
+			cache.put(e.Name, matched)
+		}
+		return decide(matched, onMatch, onMismatch)
+	}), nil
+}
+
+// MessageFilter returns a Filter which matches events whose formatted message
+// contains substr.
+func MessageFilter(substr string, onMatch, onMismatch Decision) Filter {
+	b := []byte(substr)
+	return FilterFunc(func(e *gol.LoggingEvent) Decision {
+		return decide(bytes.Contains(e.Message.Bytes(), b), onMatch, onMismatch)
+	})
+}
+
+// MessageRegexFilter returns a Filter which matches events whose formatted
+// message matches the regular expression expr.
+func MessageRegexFilter(expr string, onMatch, onMismatch Decision) (Filter, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("filter: invalid message expression %q: %v", expr, err)
+	}
+	return FilterFunc(func(e *gol.LoggingEvent) Decision {
+		return decide(re.Match(e.Message.Bytes()), onMatch, onMismatch)
+	}), nil
+}
+
+// downgradeFilter changes level of events accepted by its matcher.
+type downgradeFilter struct {
+	matcher Filter
+	level   gol.Level
+}
+
+// DowngradeFilter returns a Filter which lowers level of events accepted by
+// matcher to level, e.g. to log known noisy warnings at debug level:
+//
+//	DowngradeFilter(MessageFilter("connection reset", Accept, Neutral), gol.Debug)
+//
+// It is always neutral so following filters and checks see the new level.
+// Events are copied before being changed.
+func DowngradeFilter(matcher Filter, level gol.Level) Filter {
+	return &downgradeFilter{
+		matcher: matcher,
+		level:   level,
+	}
+}
+
+func (f *downgradeFilter) Decide(*gol.LoggingEvent) Decision {
+	return Neutral
+}
+
+func (f *downgradeFilter) Rewrite(e *gol.LoggingEvent) *gol.LoggingEvent {
+	if e.Level <= f.level || f.matcher.Decide(e) != Accept {
+		return e
+	}
+	e = e.Clone()
+	e.Level = f.level
+	return e
+}
+
+// MarkerFilter returns a Filter which matches events having any of the
+// markers or their descendants, e.g. to route audit events to a dedicated
+// appender regardless of their loggers.
+func MarkerFilter(onMatch, onMismatch Decision, markers ...gol.Marker) Filter {
+	m := make([]gol.Marker, len(markers))
+	copy(m, markers)
+	return FilterFunc(func(e *gol.LoggingEvent) Decision {
+		for _, marker := range m {
+			if e.HasMarker(marker) {
+				return onMatch
+			}
+		}
+		return onMismatch
+	})
+}
@@ -0,0 +1,98 @@
+package filter
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// maxCacheSize is the maximum number of logger names in decision cache.
+const maxCacheSize = 1024
+
+// pattern matches logger names with either a glob or a regular expression.
+type pattern struct {
+	glob string
+	re   *regexp.Regexp
+}
+
+// compilePatterns compiles patterns starting with "^" as regular expressions
+// and the others as globs (see path.Match).
+func compilePatterns(patterns []string) ([]pattern, error) {
+	compiled := make([]pattern, len(patterns))
+	for i, p := range patterns {
+		if strings.HasPrefix(p, "^") {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return nil, fmt.Errorf("filter: invalid pattern %q: %v", p, err)
+			}
+			compiled[i].re = re
+			continue
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("filter: invalid pattern %q: %v", p, err)
+		}
+		compiled[i].glob = p
+	}
+	return compiled, nil
+}
+
+func (p *pattern) match(name string) bool {
+	if p.re != nil {
+		return p.re.MatchString(name)
+	}
+	matched, _ := path.Match(p.glob, name)
+	return matched
+}
+
+func matchAny(patterns []pattern, name string) bool {
+	for i := range patterns {
+		if patterns[i].match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchHierarchy checks the logger name and its ancestors against patterns.
+func matchHierarchy(patterns []pattern, name string) bool {
+	for {
+		if matchAny(patterns, name) {
+			return true
+		}
+		idx := strings.LastIndexByte(name, packageSeparator)
+		if idx < 0 {
+			return false
+		}
+		name = name[:idx]
+	}
+}
+
+// decisionCache caches results of filtering logger names.
+type decisionCache struct {
+	mu        sync.RWMutex
+	decisions map[string]bool
+}
+
+func (c *decisionCache) get(name string) (included, ok bool) {
+	c.mu.RLock()
+	included, ok = c.decisions[name]
+	c.mu.RUnlock()
+	return
+}
+
+func (c *decisionCache) put(name string, included bool) {
+	c.mu.Lock()
+	if c.decisions == nil || len(c.decisions) >= maxCacheSize {
+		c.decisions = make(map[string]bool)
+	}
+	c.decisions[name] = included
+	c.mu.Unlock()
+}
+
+func (c *decisionCache) reset() {
+	c.mu.Lock()
+	c.decisions = nil
+	c.mu.Unlock()
+}
@@ -0,0 +1,165 @@
+package filter
+
+import (
+	"sync"
+	"time"
+
+	"github.com/goburrow/gol"
+)
+
+// rateLimit is the rate and burst of a token bucket.
+type rateLimit struct {
+	rate  float64
+	burst float64
+}
+
+// bucketKey identifies a token bucket. level is Uninitialized for the default
+// bucket shared by levels without their own limit.
+type bucketKey struct {
+	name  string
+	level gol.Level
+}
+
+// bucket is a token bucket.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take refills the bucket and takes a token if available.
+func (b *bucket) take(limit rateLimit, now time.Time) bool {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * limit.rate
+		if b.tokens > limit.burst {
+			b.tokens = limit.burst
+		}
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// RateLimitAppender caps number of events per second sent to the underlying
+// appender using token buckets. Events exceeding the limit are dropped and
+// counted.
+type RateLimitAppender struct {
+	appender gol.Appender
+
+	mu           sync.Mutex
+	defaultLimit rateLimit
+	levelLimits  map[gol.Level]rateLimit
+	perLogger    bool
+	buckets      map[bucketKey]*bucket
+	limited      map[gol.Level]uint64
+
+	now func() time.Time
+}
+
+var (
+	_ gol.Appender  = (*RateLimitAppender)(nil)
+	_ gol.Flusher   = (*RateLimitAppender)(nil)
+	_ gol.Dependent = (*RateLimitAppender)(nil)
+)
+
+// NewRateLimitAppender allocates and returns a new RateLimitAppender which
+// passes at most rate events per second with bursts of up to burst events.
+func NewRateLimitAppender(a gol.Appender, rate float64, burst int) *RateLimitAppender {
+	return &RateLimitAppender{
+		appender:     a,
+		defaultLimit: rateLimit{rate: rate, burst: float64(burst)},
+		levelLimits:  make(map[gol.Level]rateLimit),
+		buckets:      make(map[bucketKey]*bucket),
+		limited:      make(map[gol.Level]uint64),
+		now:          time.Now,
+	}
+}
+
+// SetLevelLimit gives events at the level their own limit instead of sharing
+// the default one, e.g. a higher budget for Error events.
+func (a *RateLimitAppender) SetLevelLimit(level gol.Level, rate float64, burst int) {
+	a.mu.Lock()
+	a.levelLimits[level] = rateLimit{rate: rate, burst: float64(burst)}
+	a.buckets = make(map[bucketKey]*bucket)
+	a.mu.Unlock()
+}
+
+// SetPerLogger sets whether each logger has its own limits instead of sharing
+// limits of the appender.
+func (a *RateLimitAppender) SetPerLogger(perLogger bool) {
+	a.mu.Lock()
+	a.perLogger = perLogger
+	a.buckets = make(map[bucketKey]*bucket)
+	a.mu.Unlock()
+}
+
+// Limited returns total number of events dropped by this appender.
+func (a *RateLimitAppender) Limited() uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var n uint64
+	for _, count := range a.limited {
+		n += count
+	}
+	return n
+}
+
+// LimitedByLevel returns number of events dropped by this appender for each
+// level.
+func (a *RateLimitAppender) LimitedByLevel() map[gol.Level]uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	limited := make(map[gol.Level]uint64, len(a.limited))
+	for level, count := range a.limited {
+		limited[level] = count
+	}
+	return limited
+}
+
+// Append sends the event to the underlying appender if it is within the limit.
+func (a *RateLimitAppender) Append(e *gol.LoggingEvent) {
+	now := a.now()
+
+	a.mu.Lock()
+	var k bucketKey
+	if a.perLogger {
+		k.name = e.Name
+	}
+	limit, ok := a.levelLimits[e.Level]
+	if ok {
+		k.level = e.Level
+	} else {
+		limit = a.defaultLimit
+	}
+	b := a.buckets[k]
+	if b == nil {
+		// New buckets are full.
+		b = &bucket{tokens: limit.burst, last: now}
+		a.buckets[k] = b
+	}
+	allowed := b.take(limit, now)
+	if !allowed {
+		a.limited[e.Level]++
+	}
+	a.mu.Unlock()
+
+	if allowed {
+		if err := gol.SafeAppend(a.appender, e); err != nil {
+			gol.ReportError(err)
+		}
+	}
+}
+
+// Flush flushes the underlying appender.
+func (a *RateLimitAppender) Flush() error {
+	return gol.FlushAppender(a.appender)
+}
+
+// Dependencies returns the underlying appender.
+func (a *RateLimitAppender) Dependencies() []interface{} {
+	return []interface{}{a.appender}
+}
@@ -0,0 +1,69 @@
+package filter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goburrow/gol"
+)
+
+func TestRateLimitAppender(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Now()
+
+	appender := NewRateLimitAppender(gol.NewAppender(&buf), 2, 3)
+	appender.SetLevelLimit(gol.Error, 10, 5)
+	appender.now = func() time.Time {
+		return now
+	}
+	for i := 0; i < 10; i++ {
+		appender.Append(newEvent("a", gol.Info, "info"))
+		appender.Append(newEvent("b", gol.Warn, "warn"))
+		appender.Append(newEvent("a", gol.Error, "error"))
+	}
+	// Info and Warn share the default bucket with burst 3.
+	if strings.Count(buf.String(), "info\n")+strings.Count(buf.String(), "warn\n") != 3 ||
+		strings.Count(buf.String(), "error\n") != 5 {
+		t.Fatalf("unexpected messages: %s", buf.String())
+	}
+	if appender.Limited() != 22 {
+		t.Fatalf("unexpected limited: %d", appender.Limited())
+	}
+	limited := appender.LimitedByLevel()
+	if limited[gol.Error] != 5 || limited[gol.Info]+limited[gol.Warn] != 17 {
+		t.Fatalf("unexpected limited: %v", limited)
+	}
+
+	// Refilled 2 tokens in a second.
+	buf.Reset()
+	now = now.Add(time.Second)
+	for i := 0; i < 5; i++ {
+		appender.Append(newEvent("a", gol.Info, "info"))
+	}
+	if strings.Count(buf.String(), "\n") != 2 {
+		t.Fatalf("unexpected messages: %s", buf.String())
+	}
+}
+
+func TestRateLimitAppenderPerLogger(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Now()
+
+	appender := NewRateLimitAppender(gol.NewAppender(&buf), 1, 1)
+	appender.SetPerLogger(true)
+	appender.now = func() time.Time {
+		return now
+	}
+	for i := 0; i < 3; i++ {
+		appender.Append(newEvent("a", gol.Info, "message"))
+		appender.Append(newEvent("b", gol.Info, "message"))
+	}
+	if strings.Count(buf.String(), "a: message\n") != 1 || strings.Count(buf.String(), "b: message\n") != 1 {
+		t.Fatalf("unexpected messages: %s", buf.String())
+	}
+	if appender.Limited() != 4 {
+		t.Fatalf("unexpected limited: %d", appender.Limited())
+	}
+}
@@ -0,0 +1,179 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/goburrow/gol"
+)
+
+// Window is a daily time range, e.g. from 01:00 to 03:30. If End is before
+// Start, the window ends on the next day. If Days is not empty, the window
+// only starts on those days.
+type Window struct {
+	// Start and End are durations since midnight.
+	Start time.Duration
+	End   time.Duration
+	Days  []time.Weekday
+}
+
+// ParseWindow parses a window in format "[days ]HH:MM-HH:MM", where days is a
+// comma-separated list of weekdays or ranges of weekdays, e.g.
+// "01:00-03:30", "Sat,Sun 00:00-24:00" or "Mon-Fri 22:00-02:00".
+func ParseWindow(s string) (Window, error) {
+	var w Window
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 2 {
+		return w, fmt.Errorf("filter: invalid window %q", s)
+	}
+	if len(fields) == 2 {
+		days, err := parseDays(fields[0])
+		if err != nil {
+			return w, fmt.Errorf("filter: invalid window %q: %v", s, err)
+		}
+		w.Days = days
+	}
+	times := strings.Split(fields[len(fields)-1], "-")
+	if len(times) != 2 {
+		return w, fmt.Errorf("filter: invalid window %q: expected time range", s)
+	}
+	var err error
+	if w.Start, err = parseTimeOfDay(times[0]); err != nil {
+		return w, fmt.Errorf("filter: invalid window %q: %v", s, err)
+	}
+	if w.End, err = parseTimeOfDay(times[1]); err != nil {
+		return w, fmt.Errorf("filter: invalid window %q: %v", s, err)
+	}
+	return w, nil
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	var h, m int
+	if n, err := fmt.Sscanf(s, "%d:%d", &h, &m); n != 2 || err != nil || len(s) != 5 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	if h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+func parseDays(s string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, item := range strings.Split(s, ",") {
+		bounds := strings.Split(item, "-")
+		if len(bounds) > 2 {
+			return nil, fmt.Errorf("invalid days %q", item)
+		}
+		first, ok := weekdays[strings.ToLower(bounds[0])]
+		if !ok {
+			return nil, fmt.Errorf("invalid day %q", bounds[0])
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, ok = weekdays[strings.ToLower(bounds[1])]; !ok {
+				return nil, fmt.Errorf("invalid day %q", bounds[1])
+			}
+		}
+		// Ranges can wrap around the week, e.g. Sat-Mon.
+		for d := first; ; d = (d + 1) % 7 {
+			days = append(days, d)
+			if d == last {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+// contains checks if t is in the window. t must be in the window location.
+func (w *Window) contains(t time.Time) bool {
+	// Use wall clock so windows are not shifted on daylight saving days.
+	h, m, sec := t.Clock()
+	offset := time.Duration(h)*time.Hour + time.Duration(m)*time.Minute +
+		time.Duration(sec)*time.Second + time.Duration(t.Nanosecond())
+	if w.Start <= w.End {
+		return offset >= w.Start && offset < w.End && w.startsOn(t.Weekday())
+	}
+	// The window crosses midnight.
+	if offset >= w.Start && w.startsOn(t.Weekday()) {
+		return true
+	}
+	return offset < w.End && w.startsOn((t.Weekday()+6)%7)
+}
+
+func (w *Window) startsOn(weekday time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if d == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// TimeWindowFilter matches events whose time is in any of its windows.
+type TimeWindowFilter struct {
+	windows    []Window
+	location   *time.Location
+	onMatch    Decision
+	onMismatch Decision
+
+	now func() time.Time
+}
+
+var _ Filter = (*TimeWindowFilter)(nil)
+
+// NewTimeWindowFilter allocates and returns a new TimeWindowFilter which
+// checks windows in the location, e.g. time.UTC. Local time is used if
+// location is nil.
+// For example, NewTimeWindowFilter(time.Local, Deny, Neutral, w) drops events
+// during the window and lets the following filters decide otherwise.
+func NewTimeWindowFilter(location *time.Location, onMatch, onMismatch Decision, windows ...Window) *TimeWindowFilter {
+	if location == nil {
+		location = time.Local
+	}
+	w := make([]Window, len(windows))
+	copy(w, windows)
+	return &TimeWindowFilter{
+		windows:    w,
+		location:   location,
+		onMatch:    onMatch,
+		onMismatch: onMismatch,
+		now:        time.Now,
+	}
+}
+
+// SetClock changes the clock used for events without time.
+func (f *TimeWindowFilter) SetClock(now func() time.Time) {
+	f.now = now
+}
+
+// Decide returns onMatch if the event time is in any window, or onMismatch
+// otherwise. The current time is used if the event does not have time.
+func (f *TimeWindowFilter) Decide(e *gol.LoggingEvent) Decision {
+	t := e.Time
+	if t.IsZero() {
+		t = f.now()
+	}
+	t = t.In(f.location)
+	for i := range f.windows {
+		if f.windows[i].contains(t) {
+			return f.onMatch
+		}
+	}
+	return f.onMismatch
+}
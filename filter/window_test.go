@@ -0,0 +1,94 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/goburrow/gol"
+)
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected Window
+	}{
+		{"01:00-03:30", Window{Start: time.Hour, End: 3*time.Hour + 30*time.Minute}},
+		{"Sat,sun 00:00-24:00", Window{End: 24 * time.Hour, Days: []time.Weekday{time.Saturday, time.Sunday}}},
+		{"Fri-Mon 22:00-02:00", Window{Start: 22 * time.Hour, End: 2 * time.Hour,
+			Days: []time.Weekday{time.Friday, time.Saturday, time.Sunday, time.Monday}}},
+	}
+	for _, test := range tests {
+		w, err := ParseWindow(test.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(w, test.expected) {
+			t.Fatalf("%s: unexpected window: %+v", test.s, w)
+		}
+	}
+	invalid := []string{"", "01:00", "1:00-02:00", "01:00-25:00", "01:60-02:00", "Mon Tue 01:00-02:00", "Foo 01:00-02:00", "Mon-Tue-Wed 01:00-02:00"}
+	for _, s := range invalid {
+		if _, err := ParseWindow(s); err == nil {
+			t.Fatalf("%s: error expected", s)
+		}
+	}
+}
+
+func TestTimeWindowFilter(t *testing.T) {
+	loc := time.FixedZone("AEST", 10*60*60)
+	nightly, err := ParseWindow("Mon-Fri 22:00-02:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	weekend, err := ParseWindow("Sat,Sun 12:00-13:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := NewTimeWindowFilter(loc, Deny, Neutral, nightly, weekend)
+	tests := []struct {
+		t        time.Time
+		expected Decision
+	}{
+		// Friday 2015-04-03
+		{time.Date(2015, 4, 3, 22, 0, 0, 0, loc), Deny},
+		{time.Date(2015, 4, 3, 21, 59, 59, 0, loc), Neutral},
+		// Saturday morning belongs to Friday night window.
+		{time.Date(2015, 4, 4, 1, 59, 0, 0, loc), Deny},
+		{time.Date(2015, 4, 4, 2, 0, 0, 0, loc), Neutral},
+		{time.Date(2015, 4, 4, 12, 30, 0, 0, loc), Deny},
+		// Saturday night is not in the nightly window.
+		{time.Date(2015, 4, 4, 23, 0, 0, 0, loc), Neutral},
+		// Monday morning does not belong to Sunday night.
+		{time.Date(2015, 4, 6, 1, 0, 0, 0, loc), Neutral},
+		// Time zone is converted: 2015-04-03 12:30 UTC is 22:30 AEST.
+		{time.Date(2015, 4, 3, 12, 30, 0, 0, time.UTC), Deny},
+	}
+	for _, test := range tests {
+		if d := f.Decide(&gol.LoggingEvent{Time: test.t}); d != test.expected {
+			t.Fatalf("%v: unexpected decision: %v", test.t, d)
+		}
+	}
+
+	// Clock is used for events without time.
+	f.SetClock(func() time.Time {
+		return time.Date(2015, 4, 5, 12, 0, 0, 0, loc)
+	})
+	if d := f.Decide(&gol.LoggingEvent{}); d != Deny {
+		t.Fatalf("unexpected decision: %v", d)
+	}
+}
+
+func TestTimeWindowFilterLocal(t *testing.T) {
+	w, err := ParseWindow("12:00-13:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := NewTimeWindowFilter(nil, Deny, Accept, w)
+	if d := f.Decide(&gol.LoggingEvent{Time: time.Date(2015, 4, 3, 12, 30, 0, 0, time.Local)}); d != Deny {
+		t.Fatalf("unexpected decision: %v", d)
+	}
+	if d := f.Decide(&gol.LoggingEvent{Time: time.Date(2015, 4, 3, 13, 30, 0, 0, time.Local)}); d != Accept {
+		t.Fatalf("unexpected decision: %v", d)
+	}
+}
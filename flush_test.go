@@ -0,0 +1,125 @@
+package gol
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestAppenderFlush(t *testing.T) {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	appender := NewAppender(w)
+
+	event := &LoggingEvent{
+		Name:  "flush",
+		Level: Info,
+	}
+	event.Message.WriteString("message")
+	appender.Append(event)
+	assertEquals(t, 0, buf.Len())
+	if err := appender.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, buf.String(), "flush: message\n")
+
+	if err := NewAppender(nil).Flush(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAppenderFlushPipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	if err := NewAppender(w).Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewFactory(w).Flush(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// flushAppender counts Flush calls.
+type flushAppender struct {
+	flushes int
+	err     error
+}
+
+func (*flushAppender) Append(*LoggingEvent) {}
+
+func (f *flushAppender) Flush() error {
+	f.flushes++
+	return f.err
+}
+
+func TestFactoryFlush(t *testing.T) {
+	var buf bytes.Buffer
+	factory := NewFactory(bufio.NewWriter(&buf))
+	factory.GetLogger("a").Infof("message")
+	assertEquals(t, 0, buf.Len())
+
+	shared := &flushAppender{}
+	failed := &flushAppender{err: errors.New("flush")}
+	factory.GetLogger("a/b").(*DefaultLogger).SetAppender(shared)
+	factory.GetLogger("a/c").(*DefaultLogger).SetAppender(shared)
+	factory.GetLogger("d").(*DefaultLogger).SetAppender(failed)
+
+	if err := factory.Flush(); err != failed.err {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertContains(t, buf.String(), "a: message\n")
+	assertEquals(t, 1, shared.flushes)
+	assertEquals(t, 1, failed.flushes)
+}
+
+// sliceAppender is not comparable.
+type sliceAppender []*flushAppender
+
+func (sliceAppender) Append(*LoggingEvent) {}
+
+func (a sliceAppender) Flush() error {
+	for _, f := range a {
+		f.Flush()
+	}
+	return nil
+}
+
+func TestFactoryFlushNotComparable(t *testing.T) {
+	factory := NewFactory(nil)
+	appender := sliceAppender{&flushAppender{}}
+	factory.GetLogger("a").(*DefaultLogger).SetAppender(appender)
+	factory.GetLogger("b").(*DefaultLogger).SetAppender(appender)
+
+	if err := factory.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 2, appender[0].flushes)
+
+	// Struct is comparable but its field is not.
+	factory.GetLogger("c").(*DefaultLogger).SetAppender(wrapAppender{appender})
+	factory.GetLogger("d").(*DefaultLogger).SetAppender(wrapAppender{appender})
+	if err := factory.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 6, appender[0].flushes)
+}
+
+// wrapAppender holds an appender which may not be comparable.
+type wrapAppender struct {
+	a Appender
+}
+
+func (w wrapAppender) Append(e *LoggingEvent) {
+	w.a.Append(e)
+}
+
+func (w wrapAppender) Flush() error {
+	return FlushAppender(w.a)
+}
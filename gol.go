@@ -5,8 +5,11 @@ package gol
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"runtime"
+	"runtime/debug"
+	"sync/atomic"
 )
 
 var (
@@ -16,6 +19,50 @@ var (
 	debugMode = false
 )
 
+// errorHandler contains the func(error) handling internal errors.
+var errorHandler atomic.Value
+
+// errorOutput receives internal errors when there is no error handler.
+var errorOutput io.Writer = os.Stderr
+
+// SetErrorHandler changes the handler of internal errors, e.g. panics
+// recovered from appenders. By default errors are written to standard error
+// regardless of debug mode. Set handler to nil to restore the default.
+func SetErrorHandler(handler func(error)) {
+	errorHandler.Store(handler)
+}
+
+// ReportError sends the error to the error handler.
+func ReportError(err error) {
+	if handler, ok := errorHandler.Load().(func(error)); ok && handler != nil {
+		handler(err)
+		return
+	}
+	fmt.Fprintln(errorOutput, err)
+}
+
+// PanicError is the error recovered from a panic.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("gol: panic: %v\n%s", e.Value, e.Stack)
+}
+
+// SafeAppend sends the event to the appender, returning a PanicError if the
+// appender panics.
+func SafeAppend(appender Appender, event *LoggingEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	appender.Append(event)
+	return nil
+}
+
 // GetLogger returns Logger in the default logger factory.
 func GetLogger(name string) Logger {
 	return defaultFactory.GetLogger(name)
@@ -1,6 +1,7 @@
 package gol
 
 import (
+	"bytes"
 	"errors"
 	"io/ioutil"
 	"os"
@@ -51,3 +52,43 @@ func TestDebugMode(t *testing.T) {
 		t.Fatal(string(content))
 	}
 }
+
+type panicAppender struct{}
+
+func (panicAppender) Append(*LoggingEvent) {
+	panic("append")
+}
+
+func TestSafeAppend(t *testing.T) {
+	err := SafeAppend(panicAppender{}, &LoggingEvent{})
+	perr, ok := err.(*PanicError)
+	if !ok || perr.Value != "append" || !strings.Contains(err.Error(), "gol: panic: append\n") {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	if err = SafeAppend(NewAppender(nil), &LoggingEvent{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	var errs []error
+	SetErrorHandler(func(err error) {
+		errs = append(errs, err)
+	})
+	defer SetErrorHandler(nil)
+
+	ReportError(errors.New("error"))
+	if len(errs) != 1 || errs[0].Error() != "error" {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	// Errors are written to standard error by default.
+	var buf bytes.Buffer
+	errorOutput = &buf
+	defer func() {
+		errorOutput = os.Stderr
+	}()
+	SetErrorHandler(nil)
+	ReportError(errors.New("default"))
+	assertEquals(t, "default\n", buf.String())
+}
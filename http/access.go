@@ -0,0 +1,313 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/goburrow/gol"
+)
+
+const (
+	defaultAccessLoggerName = "http/access"
+	// Time layout used in NCSA log formats.
+	clfTimeLayout = "02/Jan/2006:15:04:05 -0700"
+)
+
+// AccessLogFormat is the format of access log messages.
+type AccessLogFormat int
+
+// Access log formats
+const (
+	// CombinedFormat is the Apache/NCSA Combined Log Format. It does not
+	// include latency, use CombinedLatencyFormat instead.
+	CombinedFormat AccessLogFormat = iota
+	// JSONFormat encodes access log in JSON.
+	JSONFormat
+	// CombinedLatencyFormat is CombinedFormat followed by the time taken to
+	// serve the request in microseconds, like %D in Apache.
+	CombinedLatencyFormat
+)
+
+// AccessLog is a middleware which writes access log through a gol logger.
+// All properties must be set before calling Handler.
+type AccessLog struct {
+	// LoggerName is the name of the access logger.
+	LoggerName string
+	// Format is the format of access log messages.
+	Format AccessLogFormat
+	// Factory produces the access logger. gol.GetLogger is used if it is nil.
+	Factory gol.Factory
+
+	// levels contains logging level for each status class (1xx - 5xx).
+	levels [6]gol.Level
+	// now is used in tests.
+	now func() time.Time
+}
+
+// NewAccessLog allocates and returns a new AccessLog which logs all requests
+// at Info level in Combined Log Format.
+func NewAccessLog() *AccessLog {
+	a := &AccessLog{
+		LoggerName: defaultAccessLoggerName,
+		Format:     CombinedFormat,
+		now:        time.Now,
+	}
+	for i := range a.levels {
+		a.levels[i] = gol.Info
+	}
+	return a
+}
+
+// SetLevel changes logging level of the status class, i.e. 1 to 5 for 1xx to
+// 5xx status codes. Set level to gol.Off to disable logging for the class.
+func (a *AccessLog) SetLevel(statusClass int, level gol.Level) {
+	if statusClass > 0 && statusClass < len(a.levels) {
+		a.levels[statusClass] = level
+	}
+}
+
+// Handler returns a http.Handler which logs requests served by h.
+func (a *AccessLog) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := a.now()
+		rw := &responseWriter{ResponseWriter: w}
+		defer func() {
+			err := recover()
+			if err != nil && rw.status == 0 {
+				// The response will be written by the outer middleware
+				// recovering the panic.
+				rw.status = http.StatusInternalServerError
+			}
+			a.log(rw, r, start)
+			if err != nil {
+				panic(err)
+			}
+		}()
+		h.ServeHTTP(rw, r)
+	})
+}
+
+func (a *AccessLog) log(w *responseWriter, r *http.Request, start time.Time) {
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	level := gol.Error
+	if class := status / 100; class > 0 && class < len(a.levels) {
+		level = a.levels[class]
+	}
+	logger := a.getLogger(r)
+	if !enabled(logger, level) {
+		return
+	}
+	var buf bytes.Buffer
+	switch a.Format {
+	case JSONFormat:
+		a.writeJSON(&buf, w, r, status, start)
+	case CombinedLatencyFormat:
+		a.writeCombined(&buf, w, r, status, start)
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.FormatInt(int64(a.now().Sub(start)/time.Microsecond), 10))
+	default:
+		a.writeCombined(&buf, w, r, status, start)
+	}
+	printf(logger, level, "%s", buf.Bytes())
+}
+
+func (a *AccessLog) getLogger(r *http.Request) gol.Logger {
+	var logger gol.Logger
+	if a.Factory == nil {
+		logger = gol.GetLogger(a.LoggerName)
+	} else {
+		logger = a.Factory.GetLogger(a.LoggerName)
+	}
+	if requestID := RequestID(r.Context()); requestID != "" {
+		if l, ok := logger.(*gol.DefaultLogger); ok {
+			return l.With("request_id", requestID)
+		}
+	}
+	return logger
+}
+
+// writeCombined writes access log in NCSA Combined Log Format:
+// host ident authuser [date] "request" status bytes "referer" "user-agent"
+func (a *AccessLog) writeCombined(buf *bytes.Buffer, w *responseWriter, r *http.Request, status int, start time.Time) {
+	buf.WriteString(remoteHost(r))
+	buf.WriteString(" - ")
+	buf.WriteString(orDash(username(r)))
+	buf.WriteString(" [")
+	buf.WriteString(start.Format(clfTimeLayout))
+	buf.WriteString("] \"")
+	buf.WriteString(r.Method)
+	buf.WriteByte(' ')
+	buf.WriteString(r.RequestURI)
+	buf.WriteByte(' ')
+	buf.WriteString(r.Proto)
+	buf.WriteString("\" ")
+	buf.WriteString(strconv.Itoa(status))
+	buf.WriteByte(' ')
+	if w.size > 0 {
+		buf.WriteString(strconv.FormatInt(w.size, 10))
+	} else {
+		buf.WriteByte('-')
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(strconv.Quote(orDash(r.Referer())))
+	buf.WriteByte(' ')
+	buf.WriteString(strconv.Quote(orDash(r.UserAgent())))
+}
+
+// accessRecord is the JSON representation of an access log.
+type accessRecord struct {
+	Time       string  `json:"time"`
+	RemoteAddr string  `json:"remote_addr"`
+	User       string  `json:"user,omitempty"`
+	Method     string  `json:"method"`
+	URI        string  `json:"uri"`
+	Proto      string  `json:"proto"`
+	Status     int     `json:"status"`
+	Bytes      int64   `json:"bytes"`
+	Latency    float64 `json:"latency_ms"`
+	Referer    string  `json:"referer,omitempty"`
+	UserAgent  string  `json:"user_agent,omitempty"`
+}
+
+func (a *AccessLog) writeJSON(buf *bytes.Buffer, w *responseWriter, r *http.Request, status int, start time.Time) {
+	record := accessRecord{
+		Time:       start.Format(time.RFC3339),
+		RemoteAddr: remoteHost(r),
+		User:       username(r),
+		Method:     r.Method,
+		URI:        r.RequestURI,
+		Proto:      r.Proto,
+		Status:     status,
+		Bytes:      w.size,
+		Latency:    float64(a.now().Sub(start)) / float64(time.Millisecond),
+		Referer:    r.Referer(),
+		UserAgent:  r.UserAgent(),
+	}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(&record); err != nil {
+		gol.Print(err)
+	}
+	// Remove trailing new line added by the encoder.
+	buf.Truncate(buf.Len() - 1)
+}
+
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func username(r *http.Request) string {
+	if r.URL != nil && r.URL.User != nil {
+		return r.URL.User.Username()
+	}
+	if user, _, ok := r.BasicAuth(); ok {
+		return user
+	}
+	return ""
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// enabled checks if the level is enabled in logger.
+func enabled(logger gol.Logger, level gol.Level) bool {
+	switch level {
+	case gol.Trace:
+		return logger.TraceEnabled()
+	case gol.Debug:
+		return logger.DebugEnabled()
+	case gol.Info:
+		return logger.InfoEnabled()
+	case gol.Warn:
+		return logger.WarnEnabled()
+	case gol.Error:
+		return logger.ErrorEnabled()
+	}
+	return false
+}
+
+// printf logs message at the given level.
+func printf(logger gol.Logger, level gol.Level, format string, args ...interface{}) {
+	switch level {
+	case gol.Trace:
+		logger.Tracef(format, args...)
+	case gol.Debug:
+		logger.Debugf(format, args...)
+	case gol.Info:
+		logger.Infof(format, args...)
+	case gol.Warn:
+		logger.Warnf(format, args...)
+	case gol.Error:
+		logger.Errorf(format, args...)
+	}
+}
+
+// responseWriter records status code and number of bytes written.
+type responseWriter struct {
+	http.ResponseWriter
+
+	status int
+	size   int64
+}
+
+var (
+	_ http.Flusher  = (*responseWriter)(nil)
+	_ http.Hijacker = (*responseWriter)(nil)
+
+	errNotHijacker = errors.New("http: response writer is not a hijacker")
+)
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errNotHijacker
+	}
+	if w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return h.Hijack()
+}
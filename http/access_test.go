@@ -0,0 +1,167 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goburrow/gol"
+)
+
+func newTestAccessLog(buf *bytes.Buffer) *AccessLog {
+	a := NewAccessLog()
+	a.Factory = gol.NewFactory(buf)
+	now := time.Date(2015, time.April, 3, 2, 1, 0, 0, time.UTC)
+	a.now = func() time.Time {
+		t := now
+		now = now.Add(1500 * time.Microsecond)
+		return t
+	}
+	return a
+}
+
+func TestAccessLogCombined(t *testing.T) {
+	var buf bytes.Buffer
+	a := newTestAccessLog(&buf)
+	h := a.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+	}))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/path?q=1", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	r.SetBasicAuth("user", "password")
+	r.Header.Set("Referer", "http://example.com/")
+	r.Header.Set("User-Agent", "Go \"test\"")
+	h.ServeHTTP(w, r)
+
+	expected := `] http/access: 10.0.0.1 - user [03/Apr/2015:02:01:00 +0000] "POST /path?q=1 HTTP/1.1" 201 5 "http://example.com/" "Go \"test\""` + "\n"
+	if !strings.HasPrefix(buf.String(), "INFO  [") || !strings.HasSuffix(buf.String(), expected) {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+
+	buf.Reset()
+	h = a.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1"
+	h.ServeHTTP(w, r)
+	expected = `] http/access: 10.0.0.1 - - [03/Apr/2015:02:01:00 +0000] "GET / HTTP/1.1" 200 - "-" "-"` + "\n"
+	if !strings.HasSuffix(buf.String(), expected) {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+}
+
+func TestAccessLogCombinedLatency(t *testing.T) {
+	var buf bytes.Buffer
+	a := newTestAccessLog(&buf)
+	a.Format = CombinedLatencyFormat
+	h := a.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1"
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	expected := `] http/access: 10.0.0.1 - - [03/Apr/2015:02:01:00 +0000] "GET / HTTP/1.1" 200 - "-" "-" 1500` + "\n"
+	if !strings.HasSuffix(buf.String(), expected) {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+}
+
+func TestAccessLogJSON(t *testing.T) {
+	var buf bytes.Buffer
+	a := newTestAccessLog(&buf)
+	a.Format = JSONFormat
+	h := NewMiddleware().Handler(a.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/none", nil)
+	r.Header.Set("X-Request-ID", "abc")
+	h.ServeHTTP(w, r)
+
+	expected := `] http/access [request_id=abc]: {"time":"2015-04-03T02:01:00Z","remote_addr":"192.0.2.1","method":"GET","uri":"/none",` +
+		`"proto":"HTTP/1.1","status":404,"bytes":19,"latency_ms":1.5}` + "\n"
+	if !strings.HasSuffix(buf.String(), expected) {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+}
+
+func TestAccessLogLevel(t *testing.T) {
+	var buf bytes.Buffer
+	a := newTestAccessLog(&buf)
+	a.SetLevel(2, gol.Debug)
+	a.SetLevel(5, gol.Error)
+
+	status := http.StatusOK
+	h := a.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if buf.String() != "" {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+	status = http.StatusServiceUnavailable
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if !strings.HasPrefix(buf.String(), "ERROR [") || !strings.Contains(buf.String(), `"GET / HTTP/1.1" 503 -`) {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+}
+
+func TestAccessLogPanic(t *testing.T) {
+	var buf bytes.Buffer
+	a := newTestAccessLog(&buf)
+	h := NewMiddleware().Handler(a.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("oops")
+	})))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+	if !strings.HasPrefix(buf.String(), "INFO  [") || !strings.Contains(buf.String(), `"GET / HTTP/1.1" 500 -`) {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+}
+
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (*hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, nil
+}
+
+func TestAccessLogResponseWriter(t *testing.T) {
+	var buf bytes.Buffer
+	a := newTestAccessLog(&buf)
+	h := a.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+		if _, _, err := w.(http.Hijacker).Hijack(); err != errNotHijacker {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if !w.Flushed {
+		t.Fatal("response must be flushed")
+	}
+
+	h = a.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, err := w.(http.Hijacker).Hijack(); err != nil {
+			t.Fatal(err)
+		}
+	}))
+	buf.Reset()
+	h.ServeHTTP(&hijackRecorder{httptest.NewRecorder()}, httptest.NewRequest("GET", "/", nil))
+	if !strings.Contains(buf.String(), `"GET / HTTP/1.1" 101 -`) {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Package http provides net/http middleware which binds a request-scoped logger
+to each request.
+*/
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/goburrow/gol"
+)
+
+const (
+	defaultLoggerName        = "http"
+	defaultRequestIDHeader   = "X-Request-ID"
+	defaultTraceParentHeader = "traceparent"
+
+	// maxRequestIDLen is maximum length of request ID accepted from clients.
+	maxRequestIDLen = 128
+)
+
+type contextKey int
+
+const (
+	loggerKey contextKey = iota
+	requestIDKey
+)
+
+// Middleware binds a logger with a request ID to each request context and
+// recovers panics from the handler.
+// All properties must be set before calling Handler.
+type Middleware struct {
+	// LoggerName is the name of the logger used for requests.
+	LoggerName string
+	// RequestIDHeader is the header carrying request ID. The request ID is
+	// accepted from the request if it only contains letters, digits, '.',
+	// '_' and '-', otherwise a new one is generated.
+	// It is also set in the response.
+	RequestIDHeader string
+	// TraceParentHeader is the header carrying W3C trace context.
+	// Leave it empty to disable trace context propagation.
+	TraceParentHeader string
+	// Factory produces the request logger. gol.GetLogger is used if it is nil.
+	Factory gol.Factory
+	// GenerateID generates a new request ID. A random hex string is
+	// generated if it is nil.
+	GenerateID func() string
+}
+
+// NewMiddleware allocates and returns a new Middleware.
+func NewMiddleware() *Middleware {
+	return &Middleware{
+		LoggerName:        defaultLoggerName,
+		RequestIDHeader:   defaultRequestIDHeader,
+		TraceParentHeader: defaultTraceParentHeader,
+		GenerateID:        generateID,
+	}
+}
+
+// Handler returns a http.Handler which calls h with a request-scoped logger
+// in the request context.
+func (m *Middleware) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		var requestID string
+		if m.RequestIDHeader != "" {
+			requestID = r.Header.Get(m.RequestIDHeader)
+		}
+		if !validRequestID(requestID) {
+			if m.GenerateID == nil {
+				requestID = generateID()
+			} else {
+				requestID = m.GenerateID()
+			}
+		}
+		if m.RequestIDHeader != "" {
+			w.Header().Set(m.RequestIDHeader, requestID)
+		}
+		if m.TraceParentHeader != "" {
+			if s := r.Header.Get(m.TraceParentHeader); s != "" {
+				ctx = gol.ContextWithTraceParent(ctx, s)
+			}
+		}
+		logger := m.getLogger(ctx, requestID)
+		ctx = context.WithValue(ctx, requestIDKey, requestID)
+		ctx = NewContext(ctx, logger)
+
+		defer func() {
+			if err := recover(); err != nil {
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+				logger.Errorf("panic serving %s %s: %v\n%s", r.Method, r.URL, err, debug.Stack())
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+		}()
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// getLogger returns a logger bound to the request ID and trace context.
+func (m *Middleware) getLogger(ctx context.Context, requestID string) gol.Logger {
+	var logger gol.Logger
+	if m.Factory == nil {
+		logger = gol.GetLogger(m.LoggerName)
+	} else {
+		logger = m.Factory.GetLogger(m.LoggerName)
+	}
+	if l, ok := logger.(*gol.DefaultLogger); ok {
+		return l.WithContext(ctx).With("request_id", requestID)
+	}
+	return logger
+}
+
+// NewContext returns a copy of parent which carries logger.
+func NewContext(parent context.Context, logger gol.Logger) context.Context {
+	return context.WithValue(parent, loggerKey, logger)
+}
+
+// FromContext returns the logger stored in ctx or gol.NOPLogger if not found.
+func FromContext(ctx context.Context) gol.Logger {
+	if logger, ok := ctx.Value(loggerKey).(gol.Logger); ok {
+		return logger
+	}
+	return gol.NOPLogger
+}
+
+// RequestID returns the request ID stored in ctx.
+func RequestID(ctx context.Context) string {
+	s, _ := ctx.Value(requestIDKey).(string)
+	return s
+}
+
+// generateID returns a random 128-bit hex string.
+// validRequestID returns true if id is not empty, not too long and only
+// contains characters which are safe to log and echo in headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+			c == '.' || c == '_' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+func generateID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		gol.Print(err)
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goburrow/gol"
+)
+
+func TestMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	m := NewMiddleware()
+	m.Factory = gol.NewFactory(&buf)
+	m.LoggerName = "app/http"
+
+	var requestID string
+	h := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID = RequestID(r.Context())
+		FromContext(r.Context()).Infof("hello")
+	}))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	h.ServeHTTP(w, r)
+	if len(requestID) != 32 {
+		t.Fatalf("unexpected request id: %q", requestID)
+	}
+	if requestID != w.Header().Get("X-Request-ID") {
+		t.Fatalf("unexpected response header: %v", w.Header())
+	}
+	if !strings.HasSuffix(buf.String(), "] app/http [request_id="+requestID+"]: hello\n") {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+
+	// Accept request ID and trace context from the request.
+	buf.Reset()
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Request-ID", "abc")
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.ServeHTTP(w, r)
+	if requestID != "abc" || w.Header().Get("X-Request-ID") != "abc" {
+		t.Fatalf("unexpected request id: %q", requestID)
+	}
+	if !strings.HasSuffix(buf.String(),
+		"] app/http [trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7 request_id=abc]: hello\n") {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+}
+
+func TestMiddlewareHeader(t *testing.T) {
+	m := NewMiddleware()
+	m.Factory = gol.NewFactory(nil)
+	m.RequestIDHeader = "Request-Id"
+	m.GenerateID = func() string {
+		return "generated"
+	}
+	h := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Request-Id", strings.Repeat("a", maxRequestIDLen+1))
+	h.ServeHTTP(w, r)
+	if w.Header().Get("Request-Id") != "generated" {
+		t.Fatalf("unexpected response header: %v", w.Header())
+	}
+
+	for _, id := range []string{"abc trace_id=x", "a]b", "a=b", "a\"b", "\xff"} {
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Request-Id", id)
+		h.ServeHTTP(w, r)
+		if w.Header().Get("Request-Id") != "generated" {
+			t.Fatalf("%q: unexpected response header: %v", id, w.Header())
+		}
+	}
+}
+
+func TestMiddlewareZero(t *testing.T) {
+	var requestID string
+	h := (&Middleware{}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID = RequestID(r.Context())
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if len(requestID) != 32 {
+		t.Fatalf("unexpected request id: %q", requestID)
+	}
+}
+
+func TestMiddlewarePanic(t *testing.T) {
+	var buf bytes.Buffer
+	m := NewMiddleware()
+	m.Factory = gol.NewFactory(&buf)
+
+	h := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("oops")
+	}))
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/path", nil)
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("unexpected status: %v", w.Code)
+	}
+	msg := buf.String()
+	if !strings.HasPrefix(msg, "ERROR [") || !strings.Contains(msg, "panic serving POST /path: oops\n") ||
+		!strings.Contains(msg, "runtime/debug.Stack") {
+		t.Fatalf("unexpected message: %s", msg)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if FromContext(context.Background()) != gol.NOPLogger {
+		t.Fatal("logger must be NOPLogger")
+	}
+	logger := gol.GetLogger("test")
+	if FromContext(NewContext(context.Background(), logger)) != logger {
+		t.Fatal("unexpected logger")
+	}
+	if RequestID(context.Background()) != "" {
+		t.Fatal("request id must be empty")
+	}
+}
@@ -0,0 +1,209 @@
+/*
+Package sampler counts logging events by key in fixed intervals and sends
+summaries of events dropped in each interval. It is shared by appenders which
+suppress events, e.g. sampling.Appender and filter.DuplicateAppender.
+*/
+package sampler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/goburrow/gol"
+)
+
+// SummaryFunc returns an event reporting number of events of the key dropped
+// in an interval.
+type SummaryFunc func(key interface{}, dropped uint64) *gol.LoggingEvent
+
+// counter contains state of a key in the current interval.
+type counter struct {
+	start   time.Time
+	count   uint64
+	dropped uint64
+}
+
+// Sampler passes or drops events of each key and sends summaries to the
+// underlying appender when intervals end. Ended intervals are swept on
+// Append at most once per interval and, once started, periodically so
+// summaries are sent without new events.
+type Sampler struct {
+	appender gol.Appender
+	interval time.Duration
+	summary  SummaryFunc
+
+	mu        sync.Mutex
+	counters  map[interface{}]*counter
+	lastSweep time.Time
+	dropped   uint64
+	// finish stops the go routine sweeping periodically, which closes
+	// stopped when it exits.
+	finish  chan struct{}
+	stopped chan struct{}
+
+	now func() time.Time
+}
+
+// New allocates and returns a new Sampler.
+func New(appender gol.Appender, interval time.Duration, summary SummaryFunc) *Sampler {
+	return &Sampler{
+		appender: appender,
+		interval: interval,
+		summary:  summary,
+		counters: make(map[interface{}]*counter),
+		now:      time.Now,
+	}
+}
+
+// SetClock changes the clock, e.g. in tests.
+func (s *Sampler) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Dropped returns total number of events dropped.
+func (s *Sampler) Dropped() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// Len returns number of keys in their current interval.
+func (s *Sampler) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.counters)
+}
+
+// Append counts the event for the key and sends it to the underlying appender
+// if pass returns true for the number of events of the key in the current
+// interval, including this one.
+func (s *Sampler) Append(key interface{}, e *gol.LoggingEvent, pass func(count uint64) bool) {
+	now := s.now()
+
+	s.mu.Lock()
+	var summaries []*gol.LoggingEvent
+	if now.Sub(s.lastSweep) >= s.interval {
+		// Remove ended intervals so memory is bounded.
+		summaries = s.sweep(now, false)
+	}
+	c := s.counters[key]
+	if c != nil && now.Sub(c.start) >= s.interval {
+		if c.dropped > 0 {
+			summaries = append(summaries, s.newSummary(key, c.dropped, now))
+		}
+		c = nil
+	}
+	if c == nil {
+		c = &counter{start: now}
+		s.counters[key] = c
+	}
+	c.count++
+	passed := pass(c.count)
+	if !passed {
+		c.dropped++
+		s.dropped++
+	}
+	s.mu.Unlock()
+
+	s.appendAll(summaries)
+	if passed {
+		if err := gol.SafeAppend(s.appender, e); err != nil {
+			gol.ReportError(err)
+		}
+	}
+}
+
+// Flush ends all intervals, sends their summaries and flushes the underlying
+// appender.
+func (s *Sampler) Flush() error {
+	s.sweepAndAppend(true)
+	return gol.FlushAppender(s.appender)
+}
+
+// Start starts sweeping ended intervals periodically.
+// The underlying appender is not started.
+func (s *Sampler) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.finish != nil || s.interval <= 0 {
+		return nil
+	}
+	s.finish = make(chan struct{})
+	s.stopped = make(chan struct{})
+	go s.run(s.finish, s.stopped)
+	return nil
+}
+
+// Stop stops sweeping periodically and sends summaries of all intervals.
+// The underlying appender is not stopped.
+func (s *Sampler) Stop() error {
+	s.mu.Lock()
+	finish, stopped := s.finish, s.stopped
+	s.finish = nil
+	s.stopped = nil
+	s.mu.Unlock()
+
+	if finish != nil {
+		close(finish)
+		<-stopped
+	}
+	s.sweepAndAppend(true)
+	return nil
+}
+
+func (s *Sampler) run(finish, stopped chan struct{}) {
+	defer close(stopped)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-finish:
+			return
+		case <-ticker.C:
+			s.sweepAndAppend(false)
+		}
+	}
+}
+
+func (s *Sampler) sweepAndAppend(force bool) {
+	now := s.now()
+
+	s.mu.Lock()
+	summaries := s.sweep(now, force)
+	s.mu.Unlock()
+
+	s.appendAll(summaries)
+}
+
+// sweep removes ended intervals, or all intervals if force is true, and
+// returns summaries of their dropped events. It must be called with s.mu held.
+func (s *Sampler) sweep(now time.Time, force bool) []*gol.LoggingEvent {
+	var summaries []*gol.LoggingEvent
+	for k, c := range s.counters {
+		if !force && now.Sub(c.start) < s.interval {
+			continue
+		}
+		if c.dropped > 0 {
+			summaries = append(summaries, s.newSummary(k, c.dropped, now))
+		}
+		delete(s.counters, k)
+	}
+	s.lastSweep = now
+	return summaries
+}
+
+func (s *Sampler) newSummary(key interface{}, dropped uint64, now time.Time) *gol.LoggingEvent {
+	e := s.summary(key, dropped)
+	e.Time = now
+	return e
+}
+
+func (s *Sampler) appendAll(events []*gol.LoggingEvent) {
+	for _, e := range events {
+		if err := gol.SafeAppend(s.appender, e); err != nil {
+			gol.ReportError(err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Package json provides an appender which encodes logging events in JSON.
+*/
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/goburrow/gol"
+)
+
+const defaultTimeLayout = "2006-01-02T15:04:05.000Z07:00" // ISO8601 with milliseconds.
+
+// record is the JSON representation of a logging event.
+type record struct {
+	Time    string `json:"time"`
+	Level   string `json:"level"`
+	Logger  string `json:"logger"`
+	Message string `json:"message"`
+	TraceID string `json:"trace_id,omitempty"`
+	SpanID  string `json:"span_id,omitempty"`
+
+	Fields  map[string]string `json:"fields,omitempty"`
+	Markers []string          `json:"markers,omitempty"`
+}
+
+// Appender writes logging events to a Writer, one JSON object per line.
+type Appender struct {
+	timeLayout string
+
+	target io.Writer
+}
+
+var (
+	_ gol.Appender = (*Appender)(nil)
+	_ gol.Flusher  = (*Appender)(nil)
+)
+
+// NewAppender allocates and returns a new Appender.
+func NewAppender(target io.Writer) *Appender {
+	return &Appender{
+		timeLayout: defaultTimeLayout,
+		target:     target,
+	}
+}
+
+// Append encodes the event and writes it to the target.
+func (a *Appender) Append(event *gol.LoggingEvent) {
+	if a.target == nil {
+		return
+	}
+	r := record{
+		Time:    event.Time.Format(a.timeLayout),
+		Level:   gol.LevelString(event.Level),
+		Logger:  event.Name,
+		Message: event.Message.String(),
+		TraceID: event.TraceID,
+		SpanID:  event.SpanID,
+	}
+	if len(event.Fields) > 0 {
+		r.Fields = make(map[string]string, len(event.Fields))
+		for _, f := range event.Fields {
+			r.Fields[f.Key] = f.Value
+		}
+	}
+	if len(event.Markers) > 0 {
+		r.Markers = make([]string, len(event.Markers))
+		for i, m := range event.Markers {
+			r.Markers[i] = string(m)
+		}
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(&r); err != nil {
+		gol.Print(err)
+		return
+	}
+	if _, err := buf.WriteTo(a.target); err != nil {
+		gol.Print(err)
+	}
+}
+
+// Flush flushes the target writer if it is buffered.
+func (a *Appender) Flush() error {
+	if a.target == nil {
+		return nil
+	}
+	return gol.FlushWriter(a.target)
+}
@@ -0,0 +1,102 @@
+package json
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/goburrow/gol"
+)
+
+func TestAppender(t *testing.T) {
+	var buf bytes.Buffer
+	appender := NewAppender(&buf)
+
+	event := &gol.LoggingEvent{
+		Name:  "gol/json",
+		Level: gol.Info,
+		Time:  time.Date(2015, time.April, 3, 2, 1, 0, 789000000, time.UTC),
+	}
+	event.Message.WriteString("say \"hi\" <b>")
+	appender.Append(event)
+
+	expected := `{"time":"2015-04-03T02:01:00.789Z","level":"INFO","logger":"gol/json","message":"say \"hi\" <b>"}` + "\n"
+	if expected != buf.String() {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+}
+
+func TestAppenderWithTrace(t *testing.T) {
+	var buf bytes.Buffer
+	appender := NewAppender(&buf)
+
+	event := &gol.LoggingEvent{
+		Name:    "gol/json",
+		Level:   gol.Warn,
+		Time:    time.Date(2015, time.April, 3, 2, 1, 0, 789000000, time.UTC),
+		TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:  "00f067aa0ba902b7",
+	}
+	event.Message.WriteString("message")
+	appender.Append(event)
+
+	expected := `{"time":"2015-04-03T02:01:00.789Z","level":"WARN","logger":"gol/json","message":"message",` +
+		`"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7"}` + "\n"
+	if expected != buf.String() {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+}
+
+func TestAppenderWithFields(t *testing.T) {
+	var buf bytes.Buffer
+	appender := NewAppender(&buf)
+
+	event := &gol.LoggingEvent{
+		Name:   "gol/json",
+		Level:  gol.Error,
+		Time:   time.Date(2015, time.April, 3, 2, 1, 0, 789000000, time.UTC),
+		Fields: []gol.Field{{Key: "request_id", Value: "abc"}},
+	}
+	event.Message.WriteString("message")
+	appender.Append(event)
+
+	expected := `{"time":"2015-04-03T02:01:00.789Z","level":"ERROR","logger":"gol/json","message":"message",` +
+		`"fields":{"request_id":"abc"}}` + "\n"
+	if expected != buf.String() {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+}
+
+func TestAppenderWithMarkers(t *testing.T) {
+	var buf bytes.Buffer
+	appender := NewAppender(&buf)
+
+	event := &gol.LoggingEvent{
+		Name:    "gol/json",
+		Level:   gol.Info,
+		Time:    time.Date(2015, time.April, 3, 2, 1, 0, 789000000, time.UTC),
+		Markers: []gol.Marker{"AUDIT", "SECURITY/AUTH"},
+	}
+	event.Message.WriteString("message")
+	appender.Append(event)
+
+	expected := `{"time":"2015-04-03T02:01:00.789Z","level":"INFO","logger":"gol/json","message":"message",` +
+		`"markers":["AUDIT","SECURITY/AUTH"]}` + "\n"
+	if expected != buf.String() {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+}
+
+func TestAppenderFlushPipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	if err := NewAppender(w).Flush(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,51 @@
+package gol
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Lazy is a logging argument which value is only evaluated when the message
+// is formatted, i.e. when the logging level is enabled and the logger has an
+// appender.
+//
+//	logger.Debugf("state: %+v", gol.Lazy(func() interface{} {
+//		return dumpState()
+//	}))
+type Lazy func() interface{}
+
+var (
+	_ fmt.Formatter = Lazy(nil)
+	_ fmt.Stringer  = Lazy(nil)
+)
+
+// Format implements fmt.Formatter by formatting the evaluated value with the
+// same verb and flags.
+func (f Lazy) Format(s fmt.State, verb rune) {
+	fmt.Fprintf(s, formatSpec(s, verb), f())
+}
+
+// String returns the evaluated value in default format.
+func (f Lazy) String() string {
+	return fmt.Sprint(f())
+}
+
+// formatSpec rebuilds the format directive from the fmt.State.
+func formatSpec(s fmt.State, verb rune) string {
+	var b [16]byte
+	spec := append(b[:0], '%')
+	for _, c := range "+-# 0" {
+		if s.Flag(int(c)) {
+			spec = append(spec, byte(c))
+		}
+	}
+	if w, ok := s.Width(); ok {
+		spec = strconv.AppendInt(spec, int64(w), 10)
+	}
+	if p, ok := s.Precision(); ok {
+		spec = append(spec, '.')
+		spec = strconv.AppendInt(spec, int64(p), 10)
+	}
+	spec = append(spec, string(verb)...)
+	return string(spec)
+}
@@ -0,0 +1,46 @@
+package gol
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLazy(t *testing.T) {
+	var buf bytes.Buffer
+	count := 0
+	value := Lazy(func() interface{} {
+		count++
+		return 1.5
+	})
+
+	logger := New("lazy", nil)
+	logger.SetLevel(Info)
+	// No appender
+	logger.Infof("%v", value)
+	assertEquals(t, 0, count)
+
+	logger.SetAppender(NewAppender(&buf))
+	logger.Debugf("%v", value)
+	assertEquals(t, 0, count)
+
+	logger.Infof("%v %+07.2f", value, value)
+	assertEquals(t, 2, count)
+	if !strings.HasSuffix(buf.String(), "lazy: 1.5 +001.50\n") {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+}
+
+func TestLazyFormat(t *testing.T) {
+	value := Lazy(func() interface{} {
+		return struct{ A string }{"a"}
+	})
+	assertEquals(t, "{a}", value.String())
+	assertEquals(t, `{A:a} struct { A string }{A:"a"}`, fmt.Sprintf("%+v %#v", value, value))
+
+	value = Lazy(func() interface{} {
+		return "a"
+	})
+	assertEquals(t, "[     a|a     |\"a\"]", fmt.Sprintf("[%6v|%-6s|%q]", value, value, value))
+}
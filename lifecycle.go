@@ -0,0 +1,101 @@
+package gol
+
+import (
+	"bytes"
+)
+
+// Lifecycle is implemented by components which need to be started before use
+// and stopped afterwards, e.g. appenders holding files or connections.
+type Lifecycle interface {
+	Start() error
+	Stop() error
+}
+
+// Dependent is implemented by components using other components, e.g.
+// appenders wrapping other appenders, so they can be started after and
+// stopped before their dependencies.
+type Dependent interface {
+	// Dependencies returns components used by this component.
+	Dependencies() []interface{}
+}
+
+// Errors is a list of errors returned from many components.
+type Errors []error
+
+func (e Errors) Error() string {
+	var buf bytes.Buffer
+	for i, err := range e {
+		if i > 0 {
+			buf.WriteString("; ")
+		}
+		buf.WriteString(err.Error())
+	}
+	return buf.String()
+}
+
+// errorOrNil returns nil if there is no error.
+func (e Errors) errorOrNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// StartAll starts components and their dependencies implementing Lifecycle.
+// Dependencies are started before components using them. It continues
+// starting other components on error and returns all errors as Errors.
+func StartAll(components ...interface{}) error {
+	var errs Errors
+	for _, c := range lifecycleOrder(components) {
+		if err := c.Start(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs.errorOrNil()
+}
+
+// StopAll stops components and their dependencies implementing Lifecycle in
+// the reverse order of StartAll. It returns all errors as Errors.
+func StopAll(components ...interface{}) error {
+	var errs Errors
+	order := lifecycleOrder(components)
+	for i := len(order) - 1; i >= 0; i-- {
+		if err := order[i].Stop(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs.errorOrNil()
+}
+
+// lifecycleOrder returns distinct components implementing Lifecycle, each
+// after its dependencies.
+func lifecycleOrder(components []interface{}) []Lifecycle {
+	var order []Lifecycle
+	visited := make(map[interface{}]bool)
+	var visit func(c interface{})
+	visit = func(c interface{}) {
+		if c == nil {
+			return
+		}
+		// Only pointers are valid map keys for sure, other components are
+		// visited every time.
+		if isPointer(c) {
+			if visited[c] {
+				return
+			}
+			visited[c] = true
+		}
+		if d, ok := c.(Dependent); ok {
+			for _, dep := range d.Dependencies() {
+				visit(dep)
+			}
+		}
+		if l, ok := c.(Lifecycle); ok {
+			order = append(order, l)
+		}
+	}
+	for _, c := range components {
+		visit(c)
+	}
+	return order
+}
@@ -0,0 +1,140 @@
+package gol
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var _ Lifecycle = (*DefaultFactory)(nil)
+
+// lifecycleAppender records Start and Stop calls.
+type lifecycleAppender struct {
+	name  string
+	deps  []interface{}
+	err   error
+	calls *[]string
+}
+
+func (*lifecycleAppender) Append(*LoggingEvent) {}
+
+func (a *lifecycleAppender) Start() error {
+	*a.calls = append(*a.calls, "start "+a.name)
+	return a.err
+}
+
+func (a *lifecycleAppender) Stop() error {
+	*a.calls = append(*a.calls, "stop "+a.name)
+	return a.err
+}
+
+func (a *lifecycleAppender) Dependencies() []interface{} {
+	return a.deps
+}
+
+func TestStartStopAll(t *testing.T) {
+	var calls []string
+	policy := &lifecycleAppender{name: "policy", calls: &calls}
+	file := &lifecycleAppender{name: "file", deps: []interface{}{policy, "not a component"}, calls: &calls}
+	async := &lifecycleAppender{name: "async", deps: []interface{}{file, nil}, calls: &calls}
+
+	if err := StartAll(async, file); err != nil {
+		t.Fatal(err)
+	}
+	if err := StopAll(async, file); err != nil {
+		t.Fatal(err)
+	}
+	expected := "start policy,start file,start async,stop async,stop file,stop policy"
+	if strings.Join(calls, ",") != expected {
+		t.Fatalf("unexpected calls: %v, expected: %v", calls, expected)
+	}
+}
+
+func TestStartAllErrors(t *testing.T) {
+	var calls []string
+	a1 := &lifecycleAppender{name: "1", err: errors.New("error 1"), calls: &calls}
+	a2 := &lifecycleAppender{name: "2", calls: &calls}
+	a3 := &lifecycleAppender{name: "3", err: errors.New("error 3"), calls: &calls}
+
+	err := StartAll(a1, a2, a3)
+	errs, ok := err.(Errors)
+	if !ok || len(errs) != 2 || err.Error() != "error 1; error 3" {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	if strings.Join(calls, ",") != "start 1,start 2,start 3" {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+}
+
+func TestFactoryStartStop(t *testing.T) {
+	var calls []string
+	a1 := &lifecycleAppender{name: "1", calls: &calls}
+	a2 := &lifecycleAppender{name: "2", deps: []interface{}{a1}, calls: &calls}
+
+	factory := NewFactory(nil)
+	factory.GetLogger("a").(*DefaultLogger).SetAppender(a2)
+	factory.GetLogger("b").(*DefaultLogger).SetAppender(a1)
+	factory.GetLogger("c").(*DefaultLogger).SetAppender(a2)
+	if err := factory.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := factory.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(calls, ",") != "start 1,start 2,stop 2,stop 1" {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+}
+
+// groupAppender is not comparable.
+type groupAppender []interface{}
+
+func (groupAppender) Append(*LoggingEvent) {}
+
+func (g groupAppender) Dependencies() []interface{} {
+	return g
+}
+
+func TestFactoryStartStopNotComparable(t *testing.T) {
+	var calls []string
+	a1 := &lifecycleAppender{name: "1", calls: &calls}
+	a2 := &lifecycleAppender{name: "2", calls: &calls}
+
+	factory := NewFactory(nil)
+	factory.GetLogger("a").(*DefaultLogger).SetAppender(groupAppender{a1, a2})
+	factory.GetLogger("b").(*DefaultLogger).SetAppender(a1)
+	if err := factory.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := factory.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(calls, ",") != "start 1,start 2,stop 2,stop 1" {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+}
+
+// wrapLifecycle is comparable but holds a component which is not.
+type wrapLifecycle struct {
+	c interface{}
+}
+
+func (w wrapLifecycle) Dependencies() []interface{} {
+	return []interface{}{w.c}
+}
+
+func TestStartStopAllWrapped(t *testing.T) {
+	var calls []string
+	a1 := &lifecycleAppender{name: "1", calls: &calls}
+	w := wrapLifecycle{groupAppender{a1}}
+
+	if err := StartAll(w, w); err != nil {
+		t.Fatal(err)
+	}
+	if err := StopAll(w); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(calls, ",") != "start 1,stop 1" {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+}
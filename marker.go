@@ -0,0 +1,30 @@
+package gol
+
+import (
+	"strings"
+)
+
+// Marker is a named tag of logging events, e.g. "SECURITY" or "AUDIT",
+// independent of logger names. Like logger names, markers are hierarchical
+// with "/" as the separator, e.g. "SECURITY/AUTH" is a child of "SECURITY".
+type Marker string
+
+// Is checks if the marker is the same as or a descendant of parent.
+func (m Marker) Is(parent Marker) bool {
+	if len(m) == len(parent) {
+		return m == parent
+	}
+	return len(m) > len(parent) && m[len(parent)] == packageSeparator &&
+		strings.HasPrefix(string(m), string(parent))
+}
+
+// HasMarker checks if any marker of the event is the same as or a descendant
+// of the given marker.
+func (e *LoggingEvent) HasMarker(marker Marker) bool {
+	for _, m := range e.Markers {
+		if m.Is(marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package gol
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestMarkerIs(t *testing.T) {
+	tests := []struct {
+		marker   Marker
+		parent   Marker
+		expected bool
+	}{
+		{"SECURITY", "SECURITY", true},
+		{"SECURITY/AUTH", "SECURITY", true},
+		{"SECURITY/AUTH/LOGIN", "SECURITY", true},
+		{"SECURITYX", "SECURITY", false},
+		{"SECURITY", "SECURITY/AUTH", false},
+		{"AUDIT", "SECURITY", false},
+	}
+	for _, test := range tests {
+		if test.marker.Is(test.parent) != test.expected {
+			t.Fatalf("unexpected result: %+v", test)
+		}
+	}
+	e := &LoggingEvent{Markers: []Marker{"AUDIT", "SECURITY/AUTH"}}
+	if !e.HasMarker("SECURITY") || !e.HasMarker("AUDIT") || e.HasMarker("SECURITY/ACCESS") {
+		t.Fatalf("unexpected markers: %v", e.Markers)
+	}
+}
+
+func TestContextLoggerWithMarker(t *testing.T) {
+	var buf bytes.Buffer
+	factory := NewFactory(&buf)
+	logger := factory.GetLogger("app").(*DefaultLogger)
+
+	a := logger.WithMarker("AUDIT")
+	b := a.WithMarker("SECURITY").With("user", "alice")
+	if len(a.Markers()) != 1 || len(b.Markers()) != 2 {
+		t.Fatalf("unexpected markers: %v %v", a.Markers(), b.Markers())
+	}
+	a.Infof("a")
+	b.Warnf("b")
+	ctx := ContextWithTraceParent(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	a.WithContext(ctx).Infof("c")
+	assertContains(t, buf.String(), "] app [markers=AUDIT]: a\n",
+		"] app [markers=AUDIT,SECURITY user=alice]: b\n",
+		"] app [markers=AUDIT trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7]: c\n")
+}
+
+func TestAppendEventContext(t *testing.T) {
+	var buf bytes.Buffer
+	event := &LoggingEvent{}
+	AppendEventContext(&buf, event)
+	assertEquals(t, "", buf.String())
+
+	event.Fields = []Field{{Key: "k", Value: "v"}}
+	AppendEventContext(&buf, event)
+	assertEquals(t, " [k=v]", buf.String())
+
+	buf.Reset()
+	event.Markers = []Marker{"A", "B"}
+	event.TraceID = "x"
+	event.SpanID = "y"
+	AppendEventContext(&buf, event)
+	assertEquals(t, " [markers=A,B trace_id=x span_id=y k=v]", buf.String())
+}
+
+func TestAppendEventContextQuote(t *testing.T) {
+	var buf bytes.Buffer
+	event := &LoggingEvent{
+		Fields: []Field{
+			{Key: "a", Value: "abc trace_id=forged span_id=x] admin: login ok [x="},
+			{Key: "b", Value: "x\ny"},
+			{Key: "c", Value: "héllo"},
+		},
+	}
+	AppendEventContext(&buf, event)
+	assertEquals(t, ` [a="abc trace_id=forged span_id=x] admin: login ok [x=" b="x\ny" c=héllo]`, buf.String())
+}
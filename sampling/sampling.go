@@ -0,0 +1,129 @@
+/*
+Package sampling provides an appender which caps volume of repeated logging
+events.
+*/
+package sampling
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/goburrow/gol"
+	"github.com/goburrow/gol/internal/sampler"
+)
+
+// key identifies events sampled together.
+type key struct {
+	name   string
+	level  gol.Level
+	format string
+}
+
+// Appender lets the first N events per interval through, then every Mth
+// event, for each logger name and level (and optionally message format).
+// Number of events dropped in an interval is sent to the underlying appender
+// as a summary event after the interval ends. Once started, ended intervals
+// are checked periodically so summaries are sent even when logging stops.
+// Pending summaries are also sent on Flush and Stop.
+type Appender struct {
+	appender gol.Appender
+	sampler  *sampler.Sampler
+
+	first      uint64
+	thereafter uint64
+	interval   time.Duration
+
+	mu       sync.Mutex
+	byFormat bool
+}
+
+var (
+	_ gol.Appender  = (*Appender)(nil)
+	_ gol.Flusher   = (*Appender)(nil)
+	_ gol.Lifecycle = (*Appender)(nil)
+	_ gol.Dependent = (*Appender)(nil)
+)
+
+// NewAppender allocates and returns a new Appender which passes first events
+// per interval and then every thereafter-th event. No more events are passed
+// in the interval if thereafter is zero.
+func NewAppender(a gol.Appender, first, thereafter int, interval time.Duration) *Appender {
+	appender := &Appender{
+		appender:   a,
+		first:      uint64(first),
+		thereafter: uint64(thereafter),
+		interval:   interval,
+	}
+	appender.sampler = sampler.New(a, interval, appender.summary)
+	return appender
+}
+
+// SetSampleByFormat sets whether message format template is also used to
+// distinguish events.
+func (a *Appender) SetSampleByFormat(byFormat bool) {
+	a.mu.Lock()
+	a.byFormat = byFormat
+	a.mu.Unlock()
+}
+
+// Dropped returns total number of events dropped by this appender.
+func (a *Appender) Dropped() uint64 {
+	return a.sampler.Dropped()
+}
+
+// Append sends the event to the underlying appender if it is sampled.
+func (a *Appender) Append(e *gol.LoggingEvent) {
+	k := key{name: e.Name, level: e.Level}
+	a.mu.Lock()
+	if a.byFormat {
+		k.format = e.Format
+	}
+	a.mu.Unlock()
+	a.sampler.Append(k, e, a.sampled)
+}
+
+// Flush sends summaries of pending dropped events and flushes the underlying
+// appender.
+func (a *Appender) Flush() error {
+	return a.sampler.Flush()
+}
+
+// Start starts sending summaries of ended intervals periodically.
+// The underlying appender is not started.
+func (a *Appender) Start() error {
+	return a.sampler.Start()
+}
+
+// Stop stops sending summaries periodically and sends pending ones.
+// The underlying appender is not stopped.
+func (a *Appender) Stop() error {
+	return a.sampler.Stop()
+}
+
+// Dependencies returns the underlying appender.
+func (a *Appender) Dependencies() []interface{} {
+	return []interface{}{a.appender}
+}
+
+// sampled returns true if count-th event in an interval is passed.
+func (a *Appender) sampled(count uint64) bool {
+	return count <= a.first || (a.thereafter > 0 && (count-a.first)%a.thereafter == 0)
+}
+
+// summary returns an event reporting number of events dropped.
+func (a *Appender) summary(k interface{}, dropped uint64) *gol.LoggingEvent {
+	sk := k.(key)
+	e := &gol.LoggingEvent{
+		Name:  sk.name,
+		Level: sk.level,
+	}
+	if sk.format == "" {
+		e.Format = "sampling dropped %d events in %v"
+		fmt.Fprintf(&e.Message, e.Format, dropped, a.interval)
+	} else {
+		e.Format = "sampling dropped %d events in %v: %q"
+		fmt.Fprintf(&e.Message, e.Format, dropped, a.interval, sk.format)
+	}
+	return e
+}
@@ -0,0 +1,194 @@
+package sampling
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goburrow/gol"
+)
+
+func TestAppender(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2015, time.April, 3, 2, 1, 0, 0, time.UTC)
+
+	appender := NewAppender(gol.NewAppender(&buf), 2, 3, time.Second)
+	appender.sampler.SetClock(func() time.Time {
+		return now
+	})
+	event := &gol.LoggingEvent{
+		Name:  "sampling",
+		Level: gol.Debug,
+		Time:  now,
+	}
+	event.Message.WriteString("message")
+	for i := 0; i < 10; i++ {
+		appender.Append(event)
+	}
+	// Passed: 1, 2, 5, 8
+	if strings.Count(buf.String(), "sampling: message\n") != 4 {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+	if appender.Dropped() != 6 {
+		t.Fatalf("unexpected dropped: %d", appender.Dropped())
+	}
+	// Other level is not affected.
+	buf.Reset()
+	event.Level = gol.Info
+	appender.Append(event)
+	if !strings.HasSuffix(buf.String(), "sampling: message\n") {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+
+	// Next interval
+	buf.Reset()
+	now = now.Add(time.Second)
+	event.Level = gol.Debug
+	appender.Append(event)
+	lines := strings.Split(buf.String(), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(lines[0], "sampling: sampling dropped 6 events in 1s") ||
+		!strings.HasPrefix(lines[0], "DEBUG [2015-04-03T02:01:01.000Z]") ||
+		!strings.HasSuffix(lines[1], "sampling: message") {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+}
+
+func TestAppenderByFormat(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Now()
+
+	appender := NewAppender(gol.NewAppender(&buf), 1, 0, time.Minute)
+	appender.SetSampleByFormat(true)
+	appender.sampler.SetClock(func() time.Time {
+		return now
+	})
+	event := &gol.LoggingEvent{
+		Name:   "sampling",
+		Level:  gol.Info,
+		Format: "a %d",
+	}
+	for i := 0; i < 3; i++ {
+		appender.Append(event)
+	}
+	event.Format = "b %d"
+	for i := 0; i < 3; i++ {
+		appender.Append(event)
+	}
+	if strings.Count(buf.String(), "\n") != 2 || appender.Dropped() != 4 {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+
+	buf.Reset()
+	now = now.Add(time.Minute)
+	appender.Append(event)
+	if !strings.Contains(buf.String(), `sampling: sampling dropped 2 events in 1m0s: "b %d"`) {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+}
+
+func TestAppenderSweep(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2015, time.April, 3, 2, 1, 0, 0, time.UTC)
+
+	appender := NewAppender(gol.NewAppender(&buf), 1, 0, time.Second)
+	appender.sampler.SetClock(func() time.Time {
+		return now
+	})
+	event := &gol.LoggingEvent{
+		Name:  "a",
+		Level: gol.Info,
+	}
+	appender.Append(event)
+	appender.Append(event)
+	// Summary is sent on flush.
+	buf.Reset()
+	if err := appender.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(buf.String(), "a: sampling dropped 1 events in 1s\n") {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+	buf.Reset()
+	appender.Flush()
+	if buf.String() != "" {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+	// Ended interval of other keys is removed on next event.
+	appender.Append(event)
+	appender.Append(event)
+	now = now.Add(time.Second)
+	event.Name = "b"
+	buf.Reset()
+	appender.Append(event)
+	if !strings.HasPrefix(buf.String(), "INFO  [2015-04-03T02:01:01.000Z] a: sampling dropped 1 events in 1s\n") ||
+		!strings.HasSuffix(buf.String(), "b: \n") {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+	if appender.sampler.Len() != 1 {
+		t.Fatalf("unexpected counters: %d", appender.sampler.Len())
+	}
+}
+
+// lockedBuffer is a bytes.Buffer safe for concurrent use.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestAppenderStartStop(t *testing.T) {
+	var buf lockedBuffer
+	appender := NewAppender(gol.NewAppender(&buf), 1, 0, 10*time.Millisecond)
+	if err := appender.Start(); err != nil {
+		t.Fatal(err)
+	}
+	event := &gol.LoggingEvent{
+		Name:  "a",
+		Level: gol.Info,
+	}
+	for i := 0; i < 5; i++ {
+		appender.Append(event)
+	}
+	// Summary is sent without new events.
+	for i := 0; i < 100 && !strings.Contains(buf.String(), "sampling dropped"); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !strings.HasSuffix(buf.String(), "a: sampling dropped 4 events in 10ms\n") {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+	if err := appender.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	// Pending summaries are sent on stop.
+	appender.Append(event)
+	appender.Append(event)
+	appender.Stop()
+	if !strings.HasSuffix(buf.String(), "a: sampling dropped 1 events in 10ms\n") {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+}
+
+func TestAppenderWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := gol.New("app", nil)
+	logger.SetLevel(gol.Info)
+	logger.SetAppender(NewAppender(gol.NewAppender(&buf), 1, 0, time.Minute))
+	logger.Infof("a %d", 1)
+	logger.Infof("a %d", 2)
+	if !strings.HasSuffix(buf.String(), "app: a 1\n") {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+}
@@ -4,6 +4,7 @@ Package syslog provides logging to syslog.
 package syslog
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"net"
@@ -19,11 +20,12 @@ const (
 	// #5: Priority,
 	// #6: Hostname,
 	// #7: Tag,
-	// #8: PID.
-	defaultLayout     = "<%[5]d>%[4]s %[6]s %[7]s[%[8]d]: %[2]s: %[1]s\n"
+	// #8: PID,
+	// #9: Trace context and fields.
+	defaultLayout     = "<%[5]d>%[4]s %[6]s %[7]s[%[8]d]: %[2]s%[9]s: %[1]s\n"
 	defaultTimeLayout = time.RFC3339
 	// Layout for local syslog does not have hostname and use time.Stamp
-	localLayout     = "<%[5]d>%[4]s %[7]s[%[8]d]: %[2]s: %[1]s\n"
+	localLayout     = "<%[5]d>%[4]s %[7]s[%[8]d]: %[2]s%[9]s: %[1]s\n"
 	localTimeLayout = time.Stamp
 
 	dialTimeoutMs = 60000
@@ -87,7 +89,11 @@ type Appender struct {
 	conn io.WriteCloser
 }
 
-var _ gol.Appender = (*Appender)(nil)
+var (
+	_ gol.Appender  = (*Appender)(nil)
+	_ gol.Flusher   = (*Appender)(nil)
+	_ gol.Lifecycle = (*Appender)(nil)
+)
 
 // NewAppender allocates and returns a new Appender.
 func NewAppender() *Appender {
@@ -120,12 +126,25 @@ func (a *Appender) Append(event *gol.LoggingEvent) {
 		a.hostname,
 		a.Tag,
 		os.Getpid(),
+		eventContext(event),
 	)
 	if err != nil {
 		gol.Print(err)
 	}
 }
 
+// Flush flushes the connection if it is buffered. Messages are written
+// directly to the connection so it usually does nothing.
+func (a *Appender) Flush() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.conn == nil {
+		return nil
+	}
+	return gol.FlushWriter(a.conn)
+}
+
 // Start connects to syslog server if not connected.
 func (a *Appender) Start() error {
 	a.mu.Lock()
@@ -179,6 +198,14 @@ func (a *Appender) connect() error {
 	return nil
 }
 
+// eventContext returns markers, trace and span IDs and fields of the event to
+// be appended to the logger name.
+func eventContext(event *gol.LoggingEvent) string {
+	var buf bytes.Buffer
+	gol.AppendEventContext(&buf, event)
+	return buf.String()
+}
+
 func (a *Appender) getPriority(event *gol.LoggingEvent) int {
 	priority := int(a.Facility) * 8
 	if event.Level >= gol.Error {
@@ -62,3 +62,51 @@ func TestAppender(t *testing.T) {
 	event.Message.WriteString("message")
 	appender.Append(event)
 }
+
+func TestStubAppenderWithTrace(t *testing.T) {
+	var buf bufNopCloser
+
+	appender := NewAppender()
+	appender.Tag = "gol"
+	appender.hostname = "localhost"
+	appender.conn = &buf
+
+	event := &gol.LoggingEvent{
+		Level:   gol.Info,
+		Name:    "gol/syslog",
+		Time:    time.Now(),
+		TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:  "00f067aa0ba902b7",
+	}
+	event.Message.WriteString("message")
+
+	appender.Append(event)
+	msg := buf.String()
+	if !strings.HasSuffix(msg, "gol/syslog [trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7]: message\n") {
+		t.Fatalf("invalid message %s", msg)
+	}
+}
+
+func TestStubAppenderWithMarkers(t *testing.T) {
+	var buf bufNopCloser
+
+	appender := NewAppender()
+	appender.Tag = "gol"
+	appender.hostname = "localhost"
+	appender.conn = &buf
+
+	event := &gol.LoggingEvent{
+		Level:   gol.Info,
+		Name:    "gol/syslog",
+		Time:    time.Now(),
+		Markers: []gol.Marker{"AUDIT"},
+		Fields:  []gol.Field{{Key: "user", Value: "alice"}},
+	}
+	event.Message.WriteString("message")
+
+	appender.Append(event)
+	msg := buf.String()
+	if !strings.HasSuffix(msg, "gol/syslog [markers=AUDIT user=alice]: message\n") {
+		t.Fatalf("invalid message %s", msg)
+	}
+}
@@ -0,0 +1,105 @@
+package gol
+
+import (
+	"context"
+	"errors"
+)
+
+// TraceExtractor extracts trace and span IDs from a context.
+type TraceExtractor interface {
+	// Extract returns trace and span IDs found in the context.
+	// It returns ok = false if the context does not carry trace information.
+	Extract(ctx context.Context) (traceID, spanID string, ok bool)
+}
+
+// TraceExtractorFunc is an adapter to allow the use of ordinary functions as
+// TraceExtractor.
+type TraceExtractorFunc func(context.Context) (string, string, bool)
+
+// Extract calls f(ctx).
+func (f TraceExtractorFunc) Extract(ctx context.Context) (string, string, bool) {
+	return f(ctx)
+}
+
+var (
+	// TraceParentExtractor extracts trace and span IDs from the W3C traceparent
+	// header value stored with ContextWithTraceParent.
+	TraceParentExtractor TraceExtractor = TraceExtractorFunc(extractTraceParent)
+
+	errInvalidTraceParent = errors.New("gol: invalid traceparent")
+)
+
+type traceParentKey struct{}
+
+// ContextWithTraceParent returns a copy of parent which carries the given W3C
+// traceparent header value.
+func ContextWithTraceParent(parent context.Context, traceParent string) context.Context {
+	return context.WithValue(parent, traceParentKey{}, traceParent)
+}
+
+// TraceParentFromContext returns the traceparent header value stored in ctx.
+func TraceParentFromContext(ctx context.Context) string {
+	s, _ := ctx.Value(traceParentKey{}).(string)
+	return s
+}
+
+func extractTraceParent(ctx context.Context) (string, string, bool) {
+	s := TraceParentFromContext(ctx)
+	if s == "" {
+		return "", "", false
+	}
+	traceID, spanID, err := ParseTraceParent(s)
+	if err != nil {
+		Print(err)
+		return "", "", false
+	}
+	return traceID, spanID, true
+}
+
+// ParseTraceParent parses a W3C traceparent header value
+// (e.g. 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01)
+// and returns its trace ID and parent (span) ID.
+func ParseTraceParent(s string) (traceID, spanID string, err error) {
+	// version "-" trace-id "-" parent-id "-" trace-flags
+	const length = 2 + 1 + 32 + 1 + 16 + 1 + 2
+	if len(s) < length {
+		return "", "", errInvalidTraceParent
+	}
+	version := s[0:2]
+	if !isLowerHex(version) || version == "ff" {
+		return "", "", errInvalidTraceParent
+	}
+	// Future versions may append more fields.
+	if len(s) > length && (version == "00" || s[length] != '-') {
+		return "", "", errInvalidTraceParent
+	}
+	if s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return "", "", errInvalidTraceParent
+	}
+	traceID = s[3:35]
+	spanID = s[36:52]
+	if !isLowerHex(traceID) || !isLowerHex(spanID) || !isLowerHex(s[53:55]) ||
+		isZeros(traceID) || isZeros(spanID) {
+		return "", "", errInvalidTraceParent
+	}
+	return traceID, spanID, nil
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func isZeros(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '0' {
+			return false
+		}
+	}
+	return true
+}
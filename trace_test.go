@@ -0,0 +1,56 @@
+package gol
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	traceID, spanID, err := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+	assertEquals(t, "00f067aa0ba902b7", spanID)
+
+	// Future version with extra fields.
+	traceID, spanID, err = ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+	assertEquals(t, "00f067aa0ba902b7", spanID)
+
+	invalids := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0x",
+		"00_4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	}
+	for _, s := range invalids {
+		if _, _, err = ParseTraceParent(s); err == nil {
+			t.Fatalf("expected error for %q", s)
+		}
+	}
+}
+
+func TestTraceParentExtractor(t *testing.T) {
+	ctx := context.Background()
+	_, _, ok := TraceParentExtractor.Extract(ctx)
+	assertEquals(t, false, ok)
+
+	ctx = ContextWithTraceParent(ctx, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	traceID, spanID, ok := TraceParentExtractor.Extract(ctx)
+	assertEquals(t, true, ok)
+	assertEquals(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+	assertEquals(t, "00f067aa0ba902b7", spanID)
+
+	ctx = ContextWithTraceParent(ctx, "invalid")
+	_, _, ok = TraceParentExtractor.Extract(ctx)
+	assertEquals(t, false, ok)
+}